@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"os/user"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -29,18 +30,40 @@ func init() {
 
 func main() {
 	showFiles := flag.Bool("show-files", false, "Show changed files for reviewing")
-	verbose := flag.Bool("verbose", false, "Show progress and errors information")
+	showRank := flag.Bool("show-rank", false, "Number reviewers by rank")
+	verbose := flag.Bool("verbose", false, "Show progress, errors, and each git command run on stderr")
 	force := flag.Bool("force", false, "Continue processing despite checks or errors")
 	since := flag.String("since", "", "Consider commits after date when finding"+
-		" reviewers. Defaults to 6 months ago (format 'YYYY-MM-DD')")
+		" reviewers. Defaults to 6 months ago, or far enough back to cover the"+
+		" last 100 commits (format 'YYYY-MM-DD')")
 	ie := flag.String("ignore-extension", "", "Exclude changed paths that end with"+
 		" these extensions (--ignore-extension svg,png,jpg)")
 	oe := flag.String("only-extension", "", "Only consider changed paths that end with"+
 		" one of these extensions (--only-extension go,js)")
-	ip := flag.String("ignore-path", "", "Exclude file or files under path"+
-		" (--ignore-path main.go,src)")
-	op := flag.String("only-path", "", "Only consider file or files under path"+
-		" (--only-path main.go,src)")
+	ol := flag.String("only-language", "", "Only consider changed files written in"+
+		" one of these languages (--only-language Go,C++)")
+	ignoreImport := flag.Bool("ignore-import", false, "Give no credit for the first"+
+		" commit, such as one importing the project from another VCS")
+	ip := flag.String("ignore-path", "", "Exclude file or files under path, or matching a glob"+
+		" (--ignore-path main.go,src,'**/testdata/')")
+	op := flag.String("only-path", "", "Only consider file or files under path, or matching a glob"+
+		" (--only-path main.go,src,'internal/**')")
+	exclude := flag.String("exclude", "", "Leave out reviewers whose email or name"+
+		" contains any of these (--exclude bot,alice@example.com)")
+	includeBots := flag.Bool("include-bots", false, "Suggest bots such as"+
+		" dependabot[bot] along with people")
+	excludeSelf := flag.Bool("exclude-self", false, "Leave yourself out of the"+
+		" suggested reviewers")
+	self := flag.String("self", "", "Email to treat as yourself with"+
+		" --exclude-self. Defaults to git config user.email")
+	base := flag.String("base", "", "Branch, tag, or commit to compare"+
+		" the current branch against. Defaults to origin/HEAD, or master")
+	maxReviewers := flag.Int("max-reviewers", 0, "How many reviewers to suggest."+
+		" Defaults to 3")
+	maxFiles := flag.Int("max-files", 0, "Skip finding reviewers when more files"+
+		" than this have changed. Defaults to no limit")
+	maxWidth := flag.Int("max-width", 0, "Cut long reviewers short to keep lines"+
+		" within this many characters. Defaults to no limit")
 	v := flag.Bool("version", false, "Print the program version and exit")
 
 	flag.Parse()
@@ -62,6 +85,8 @@ func main() {
 	onlyExtensions := strings.FieldsFunc(*oe, spaceOrComma)
 	ignoredPaths := strings.FieldsFunc(*ip, spaceOrComma)
 	onlyPaths := strings.FieldsFunc(*op, spaceOrComma)
+	onlyLanguages := strings.FieldsFunc(*ol, spaceOrComma)
+	excluded := strings.FieldsFunc(*exclude, spaceOrComma)
 
 	err := checkDateArg(*since)
 	if len(*since) > 0 && err != nil {
@@ -81,17 +106,60 @@ func main() {
 		return
 	}
 
-	r := gr.ContributionCounter{
-		Repo:              repo,
-		ShowFiles:         *showFiles,
-		Verbose:           *verbose,
-		Since:             *since,
-		IgnoredExtensions: ignoredExtensions,
-		OnlyExtensions:    onlyExtensions,
-		IgnoredPaths:      ignoredPaths,
-		OnlyPaths:         onlyPaths,
+	// Options checked in to the repository apply unless overridden by flags
+	cfg, err := gr.LoadConfig(filepath.Join(dir, gr.ConfigFileName))
+	if err != nil {
+		fmt.Printf("Unable to load %s: %v\n", gr.ConfigFileName, err)
+		return
+	}
+
+	r := *cfg
+	r.Repo = repo
+	r.OnUnrelatedBase = func(base string) {
+		fmt.Printf("Warning: %s is not an ancestor of the current branch\n", base)
 	}
 
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "show-files":
+			r.ShowFiles = *showFiles
+		case "show-rank":
+			r.ShowRank = *showRank
+		case "verbose":
+			r.Verbose = *verbose
+		case "since":
+			r.Since = *since
+		case "ignore-extension":
+			r.IgnoredExtensions = ignoredExtensions
+		case "only-extension":
+			r.OnlyExtensions = onlyExtensions
+		case "ignore-path":
+			r.IgnoredPaths = ignoredPaths
+		case "only-path":
+			r.OnlyPaths = onlyPaths
+		case "only-language":
+			r.OnlyLanguages = onlyLanguages
+		case "ignore-import":
+			r.IgnoreImportCommit = *ignoreImport
+		case "base":
+			r.BaseBranch = *base
+		case "exclude-self":
+			r.ExcludeSelf = *excludeSelf
+		case "exclude":
+			r.ExcludedReviewers = excluded
+		case "include-bots":
+			r.IncludeBots = *includeBots
+		case "max-files":
+			r.MaxDiffFiles = *maxFiles
+		case "max-reviewers":
+			r.MaxReviewers = *maxReviewers
+		case "max-width":
+			r.MaxWidth = *maxWidth
+		case "self":
+			r.SelfIdentity = *self
+		}
+	})
+
 	// TODO take mailmap paths from command args
 	var mailmapPaths []string
 	if u, err := user.Current(); err == nil {
@@ -110,7 +178,11 @@ func main() {
 			return
 		}
 
-		fmt.Println("Current branch is behind master. Merge up!")
+		if len(r.BaseBranch) > 0 {
+			fmt.Printf("Current branch is behind %s. Merge up!\n", r.BaseBranch)
+		} else {
+			fmt.Println("Current branch is behind its base. Merge up!")
+		}
 		if *force == false {
 			return
 		}
@@ -129,12 +201,20 @@ func main() {
 		return
 	}
 
-	if *showFiles {
+	if r.ShowFiles {
 		fmt.Println("Reviewers across the following changed files:")
 		for _, file := range files {
 			fmt.Printf("  %s\n", file)
 		}
 		fmt.Println()
+
+		if introduced, err := r.FindIntroducedFiles(); err == nil && len(introduced) > 0 {
+			fmt.Println("New files with no other collaborators:")
+			for _, file := range introduced {
+				fmt.Printf("  %s\n", file)
+			}
+			fmt.Println()
+		}
 	}
 
 	// Find the best reviewers for these files.
@@ -144,12 +224,19 @@ func main() {
 		case gr.NoReviewersErr:
 			fmt.Printf("Problem finding reviewers: %s", e.Help())
 			fmt.Println("Run git-reviwer again with the --since argument")
+		case gr.ErrDiffTooLarge:
+			fmt.Printf("Skipping reviewers: %v\n", e)
 		default:
 			fmt.Printf("There was an error finding reviewers: %v\n", err)
 		}
 		return
 	}
 
+	if len(reviewers) == 0 {
+		fmt.Println("No reviewers found besides those excluded")
+		return
+	}
+
 	fmt.Println(reviewers)
 }
 
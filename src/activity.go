@@ -0,0 +1,44 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"math"
+)
+
+// activityWeights scales each path by how actively it is changing. A file
+// that many people have been committing to since Since is in flux and needs
+// reviewers who know it as it is now, whereas experience with a file nobody
+// touches counts for less. Each file is weighted by
+//
+//	1 + ln(1 + commits * distinct authors)
+//
+// so a file with no recent commits keeps a weight of 1, and the weight grows
+// slowly as activity increases.
+func (r *ContributionCounter) activityWeights(paths []string) (map[string]float64, error) {
+	records, err := r.ContributionRecords(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make(map[string]int)
+	authors := make(map[string]map[string]bool)
+	for _, rec := range records {
+		commits[rec.File]++
+		if authors[rec.File] == nil {
+			authors[rec.File] = make(map[string]bool)
+		}
+		authors[rec.File][rec.Email] = true
+	}
+
+	weights := make(map[string]float64)
+	for _, p := range paths {
+		activity := float64(commits[p] * len(authors[p]))
+		weights[p] = 1 + math.Log1p(activity)
+	}
+
+	return weights, nil
+}
@@ -0,0 +1,47 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+)
+
+func TestActivityWeight(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// bob owns more lines, but only in a file nobody else has touched. abe's
+	// file has seen steady work from several people.
+	f.commit("bob@git-reviewer.com", map[string]string{"dormant.go": "1\n2\n3\n4\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"active.go": "1\n2\n3\n"})
+	f.commit("carol@git-reviewer.com", map[string]string{"active.go": "1\n2\n3\n4\n"})
+	f.commit("dave@git-reviewer.com", map[string]string{"active.go": "1\n2\n3\n4\n5\n"})
+	f.commit("erin@git-reviewer.com", map[string]string{"active.go": "1\n2\n3\n4\n5\n6\n"})
+
+	paths := []string{"dormant.go", "active.go"}
+	cases := []struct {
+		Activity bool
+		Top      string
+	}{
+		{false, "bob@git-reviewer.com"},
+		{true, "abe@git-reviewer.com"},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.ActivityWeight = c.Activity
+
+		stats, err := r.FindReviewerStats(paths)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if stats[0].Reviewer != c.Top {
+			t.Errorf("Got top reviewer %s with activity weighting %v, expected %s\n",
+				stats[0].Reviewer, c.Activity, c.Top)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"sort"
+	"strings"
+)
+
+// mergeEmailPrefixes adds the aliases emailAlias finds among everyone who
+// committed to the base since Since to the mailmap, so they are counted as
+// one reviewer under the shortest of their emails.
+func (r *ContributionCounter) mergeEmailPrefixes() error {
+	authors, err := r.recentAuthors()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]map[string]bool)
+	for _, a := range authors {
+		if len(a.name) == 0 {
+			continue
+		}
+
+		if byName[a.name] == nil {
+			byName[a.name] = make(map[string]bool)
+		}
+		byName[a.name][reviewerKey(a.email, r.Mailmap)] = true
+	}
+
+	// Shorter emails are settled first, so each alias points at the end of
+	// its chain, as jane.doe.jr does at jane by way of jane.doe
+	aliases := make(map[string]string)
+	for _, set := range byName {
+		var emails []string
+		for email := range set {
+			emails = append(emails, email)
+		}
+		sort.Slice(emails, func(i, j int) bool {
+			if len(emails[i]) != len(emails[j]) {
+				return len(emails[i]) < len(emails[j])
+			}
+			return emails[i] < emails[j]
+		})
+
+		for i, email := range emails {
+			for _, shorter := range emails[:i] {
+				if emailAlias(shorter, email) {
+					canonical := shorter
+					if c, ok := aliases[shorter]; ok {
+						canonical = c
+					}
+					aliases[email] = canonical
+					break
+				}
+			}
+		}
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	merged := make(mailmap, len(r.Mailmap)+len(aliases))
+	for from, to := range r.Mailmap {
+		if canonical, ok := aliases[to]; ok {
+			to = canonical
+		}
+		merged[from] = to
+	}
+	for from, to := range aliases {
+		merged[from] = to
+	}
+	r.Mailmap = merged
+
+	return nil
+}
+
+// emailAlias conservatively decides whether long is another address for the
+// same person as short, given they commit under the same name: both have to
+// be at the same domain, with short's local part starting long's and
+// followed by a separator, as jane@corp.com is to jane.doe@corp.com. Neither
+// jane@corp.com and janet@corp.com nor jane@corp.com and jane.doe@home.com
+// qualify.
+func emailAlias(short, long string) bool {
+	si, li := strings.LastIndex(short, "@"), strings.LastIndex(long, "@")
+	if si <= 0 || li <= 0 || !strings.EqualFold(short[si:], long[li:]) {
+		return false
+	}
+
+	sLocal, lLocal := strings.ToLower(short[:si]), strings.ToLower(long[:li])
+	if len(lLocal) <= len(sLocal) || !strings.HasPrefix(lLocal, sLocal) {
+		return false
+	}
+
+	return strings.ContainsRune(".-_+", rune(lLocal[len(sLocal)]))
+}
@@ -0,0 +1,80 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+)
+
+func TestMergeEmailPrefixes(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commitAs("Jane Doe", "jane@corp.com", map[string]string{"a.go": "1\n2\n"})
+	f.commitAs("Jane Doe", "jane.doe@corp.com", map[string]string{"b.go": "1\n"})
+	f.commitAs("Jane Doe", "Jane.Doe.Jr@corp.com", map[string]string{"c.go": "1\n"})
+	// Not her: another name, a name that merely starts the same, and
+	// another domain
+	f.commitAs("Jane Roe", "jane.roe@corp.com", map[string]string{"d.go": "1\n"})
+	f.commitAs("Janet Doe", "janet@corp.com", map[string]string{"e.go": "1\n"})
+	f.commitAs("Jane Doe", "jane.doe@home.com", map[string]string{"g.go": "1\n"})
+
+	paths := []string{"a.go", "b.go", "c.go", "d.go", "e.go", "g.go"}
+	cases := []struct {
+		Merge    bool
+		Expected map[string]int64
+	}{
+		{false, map[string]int64{
+			"jane@corp.com": 2, "jane.doe@corp.com": 1, "Jane.Doe.Jr@corp.com": 1,
+			"jane.roe@corp.com": 1, "janet@corp.com": 1, "jane.doe@home.com": 1,
+		}},
+		{true, map[string]int64{
+			"jane@corp.com": 4, "jane.roe@corp.com": 1, "janet@corp.com": 1, "jane.doe@home.com": 1,
+		}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.MaxReviewers = 10
+		r.MergeEmailPrefixes = c.Merge
+
+		stats, err := r.FindReviewerStats(paths)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Errorf("Got %v merging %v, expected %v\n", stats, c.Merge, c.Expected)
+		}
+		for _, stat := range stats {
+			if expected, ok := c.Expected[stat.Reviewer]; !ok || stat.Count != expected {
+				t.Errorf("Got %d for %s merging %v, expected %d\n", stat.Count, stat.Reviewer, c.Merge, expected)
+			}
+		}
+	}
+}
+
+func TestEmailAlias(t *testing.T) {
+	cases := []struct {
+		Short, Long string
+		Alias       bool
+	}{
+		{"jane@corp.com", "jane.doe@corp.com", true},
+		{"jane@corp.com", "jane_doe@CORP.com", true},
+		{"jane@corp.com", "jane+ci@corp.com", true},
+		{"jane@corp.com", "janet@corp.com", false},
+		{"jane@corp.com", "jane.doe@home.com", false},
+		{"jane@corp.com", "jane@corp.com", false},
+		{"@corp.com", ".jane@corp.com", false},
+		{"jane", "jane.doe", false},
+	}
+
+	for _, c := range cases {
+		if actual := emailAlias(c.Short, c.Long); actual != c.Alias {
+			t.Errorf("Got %v for %s and %s, expected %v\n", actual, c.Short, c.Long, c.Alias)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Backend reads the history experience is drawn from. ShellBackend, the
+// default, runs the git binary; GoGitBackend reads the repository without
+// it. With any Backend but ShellBackend, everything else the counter reads
+// from the repository, such as the default Since or CODEOWNERS, is read
+// with go-git too.
+type Backend interface {
+	// Resolve finds the commit a revision such as a branch, tag, or hash
+	// names.
+	Resolve(r *ContributionCounter, rev string) (plumbing.Hash, error)
+
+	// Blame attributes every line of path as of the commit rev.
+	Blame(r *ContributionCounter, rev plumbing.Hash, path string) ([]BlameLine, error)
+
+	// Log returns a Contribution for every change to one of paths by the
+	// non-merge commits reachable from rev since the day since, newest first
+	// for each path.
+	// Emails are as committed; the counter applies its mailmap.
+	Log(r *ContributionCounter, rev plumbing.Hash, since string, paths []string) ([]Contribution, error)
+}
+
+// BlameLine is who last changed a line of a file, in which commit, and on
+// which day ("YYYY-MM-DD") in the author's time zone. When is the moment of
+// the change, for finding the day elsewhere; it may be zero if not known.
+type BlameLine struct {
+	Email string
+	Rev   string
+	Date  string
+	When  time.Time
+}
+
+// backend returns the Backend in use.
+func (r *ContributionCounter) backend() Backend {
+	if r.Backend == nil {
+		return ShellBackend{}
+	}
+
+	return r.Backend
+}
+
+// runsGit reports whether the Backend runs the git binary, so everything
+// else may too. Otherwise the counter mustn't need it at all.
+func (r *ContributionCounter) runsGit() bool {
+	switch r.backend().(type) {
+	case ShellBackend, *ShellBackend:
+		return true
+	}
+
+	return false
+}
+
+// ShellBackend runs the git binary, through CommandPrefix when it is set.
+type ShellBackend struct{}
+
+// check makes sure git can find the repository at all, so that isn't
+// mistaken for the repository being empty.
+func (ShellBackend) check(r *ContributionCounter) error {
+	if _, err := r.git("rev-parse", "--git-dir"); err != nil {
+		return errors.Wrap(err, "unable to find git repository")
+	}
+
+	return nil
+}
+
+// Resolve lets git verify the revision and peel it, so annotated tags name
+// the commits they point at.
+func (ShellBackend) Resolve(r *ContributionCounter, rev string) (plumbing.Hash, error) {
+	out, err := r.git("rev-parse", "--verify", "-q", rev+"^{commit}")
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return plumbing.NewHash(string(out)), nil
+}
+
+// Blame runs git blame on the file.
+func (ShellBackend) Blame(r *ContributionCounter, rev plumbing.Hash, path string) ([]BlameLine, error) {
+	// Full hashes (-l) let attributions be matched up with other commit data.
+	// Lines moved around within the file (-M), or from other files (-C), are
+	// credited to whoever wrote them rather than whoever moved them.
+	args := []string{"blame", "-cel", "-M"}
+	if r.DetectCopies {
+		args = append(args, "-C")
+	}
+
+	// The path is given after "--" so git doesn't take it for a revision
+	// when it isn't in the working tree, as in sparse checkouts.
+	out, err := r.output(r.gitCommand(append(args, rev.String(), "--", path)...))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git blame command")
+	}
+
+	var lines []BlameLine
+	scn := bufio.NewScanner(bytes.NewReader(out))
+	for scn.Scan() {
+		bi, err := parseBlameLine(scn.Bytes())
+		if err != nil {
+			return nil, errors.Wrap(err, "issue parsing a line in git blame output")
+		}
+
+		lines = append(lines, BlameLine{
+			Email: string(bi.email),
+			Rev:   strings.TrimPrefix(string(bi.rev), "^"),
+			Date:  string(bi.date),
+			When:  bi.when,
+		})
+	}
+
+	return lines, scn.Err()
+}
+
+// Log runs git log with --numstat over the paths, along with any
+// ExtraLogArgs. Paths that were renamed are logged one at a time with
+// --follow, which is all it can take, so their history under earlier names
+// counts, but not that of unrelated files that took those names later.
+// Records are newest first for each path.
+func (ShellBackend) Log(r *ContributionCounter, rev plumbing.Hash, since string, paths []string) ([]Contribution, error) {
+	if err := checkExtraLogArgs(r.ExtraLogArgs); err != nil {
+		return nil, err
+	}
+
+	renamed, err := renamedPaths(r, rev, since, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain, followed []string
+	for _, p := range paths {
+		if renamed[p] {
+			followed = append(followed, p)
+		} else {
+			plain = append(plain, p)
+		}
+	}
+
+	var records []Contribution
+	if len(plain) > 0 {
+		if records, err = shellLog(r, rev, since, nil, plain); err != nil {
+			return nil, err
+		}
+		for i := range records {
+			records[i].File = renamedPath(records[i].File)
+		}
+	}
+
+	for _, p := range followed {
+		found, err := shellLog(r, rev, since, []string{"--follow"}, []string{p})
+		if err != nil {
+			return nil, err
+		}
+		for i := range found {
+			found[i].File = p
+		}
+		records = append(records, found...)
+	}
+
+	return records, nil
+}
+
+// shellLog runs git log with --numstat and any extra options over the paths,
+// and parses what it finds.
+func shellLog(r *ContributionCounter, rev plumbing.Hash, since string, extra, paths []string) ([]Contribution, error) {
+	args := []string{"log", "--no-merges", "-M", "--numstat", recordFormat}
+	args = append(append(args, extra...), r.ExtraLogArgs...)
+	args = append(args, "--since", r.gitDate(since), rev.String(), "--")
+	out, err := r.git(append(args, paths...)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git log command")
+	}
+
+	return parseRecords(out)
+}
+
+// GoGitBackend reads the repository with go-git, for environments without
+// the git binary. It gives the same results as ShellBackend, except that
+// blame doesn't follow lines moved or copied between places, logs don't
+// follow renames, ExtraLogArgs aren't supported, Since is taken to start at
+// midnight local time, and only the repository's own git config is read for
+// who is running the analysis.
+type GoGitBackend struct{}
+
+// goGitMu serializes GoGitBackend's blames and logs, since go-git's object
+// caches aren't safe to share between the goroutines generateCounts starts.
+var goGitMu sync.Mutex
+
+// Resolve looks the revision up and peels annotated tags.
+func (GoGitBackend) Resolve(r *ContributionCounter, rev string) (plumbing.Hash, error) {
+	goGitMu.Lock()
+	defer goGitMu.Unlock()
+
+	h, err := r.Repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if tag, err := r.Repo.TagObject(*h); err == nil {
+		c, err := tag.Commit()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return c.Hash, nil
+	}
+
+	if _, err := r.Repo.CommitObject(*h); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return *h, nil
+}
+
+// Blame uses go-git's blame.
+func (GoGitBackend) Blame(r *ContributionCounter, rev plumbing.Hash, path string) ([]BlameLine, error) {
+	goGitMu.Lock()
+	defer goGitMu.Unlock()
+
+	c, err := r.Repo.CommitObject(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := gogit.Blame(c, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to run go-git blame")
+	}
+
+	lines := make([]BlameLine, len(result.Lines))
+	for i, line := range result.Lines {
+		lines[i] = BlameLine{
+			Email: line.Author,
+			Rev:   line.Hash.String(),
+			Date:  line.Date.Format("2006-01-02"),
+			When:  line.Date,
+		}
+	}
+
+	return lines, nil
+}
+
+// Log walks the history in committer time order, as git log does, diffing
+// each commit against its parent.
+func (GoGitBackend) Log(r *ContributionCounter, rev plumbing.Hash, since string, paths []string) ([]Contribution, error) {
+	if len(r.ExtraLogArgs) > 0 {
+		return nil, errors.New("ExtraLogArgs need the git binary, which GoGitBackend doesn't use")
+	}
+
+	var records []Contribution
+	err := r.walkHistory(rev, since, func(c *object.Commit) error {
+		if c.NumParents() > 1 {
+			return nil
+		}
+
+		stats, err := changedStats(c, paths)
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range stats {
+			records = append(records, Contribution{
+				Author:  c.Author.Name,
+				Email:   c.Author.Email,
+				File:    stat.Name,
+				SHA:     c.Hash.String(),
+				Added:   int64(stat.Addition),
+				Deleted: int64(stat.Deletion),
+				When:    c.Author.When,
+			})
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// walkHistory calls fn with every commit reachable from rev whose commit date
+// is on or after the day since, newest first as git log lists them. An empty
+// since walks the whole history. fn mustn't use the Backend, which is locked
+// out until the walk ends.
+func (r *ContributionCounter) walkHistory(rev plumbing.Hash, since string, fn func(*object.Commit) error) error {
+	var from time.Time
+	if len(since) > 0 {
+		var err error
+		if from, err = time.ParseInLocation("2006-01-02", since, r.location()); err != nil {
+			return errors.Wrapf(err, "unable to parse since '%s'", since)
+		}
+	}
+
+	goGitMu.Lock()
+	defer goGitMu.Unlock()
+
+	iter, err := r.Repo.Log(&gogit.LogOptions{From: rev, Order: gogit.LogOrderCommitterTime})
+	if err != nil {
+		return errors.Wrap(err, "unable to read history")
+	}
+	defer iter.Close()
+
+	for {
+		c, err := iter.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "unable to read history")
+		}
+
+		if c.Committer.When.Before(from) {
+			continue
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+}
+
+// firstParents follows the first parents of c, as git log --first-parent
+// does, returning up to n commits starting with c.
+func firstParents(c *object.Commit, n int) ([]*object.Commit, error) {
+	goGitMu.Lock()
+	defer goGitMu.Unlock()
+
+	var found []*object.Commit
+	for {
+		found = append(found, c)
+		if len(found) >= n || c.NumParents() == 0 {
+			return found, nil
+		}
+
+		var err error
+		if c, err = c.Parent(0); err != nil {
+			return nil, errors.Wrapf(err, "unable to open parent of %s", c.Hash)
+		}
+	}
+}
+
+// parentTree opens the tree of a commit's first parent, or returns nil for a
+// commit without parents.
+func parentTree(c *object.Commit) (*object.Tree, error) {
+	if c.NumParents() == 0 {
+		return nil, nil
+	}
+
+	p, err := c.Parent(0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open parent of %s", c.Hash)
+	}
+
+	tree, err := p.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open parent tree of %s", c.Hash)
+	}
+
+	return tree, nil
+}
+
+// changedStats counts the lines a commit added to and deleted from each of
+// the files it changed under paths, compared to its first parent.
+func changedStats(c *object.Commit, paths []string) (object.FileStats, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open tree of %s", c.Hash)
+	}
+
+	parent, err := parentTree(c)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := treeStats(parent, tree, paths)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to diff %s against its parent", c.Hash)
+	}
+
+	return stats, nil
+}
+
+// commitPaths lists the files a commit changed, or with added only the ones
+// it created, compared to its first parent.
+func commitPaths(c *object.Commit, added bool) ([]string, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open tree of %s", c.Hash)
+	}
+
+	parent, err := parentTree(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return changedPaths(parent, tree, added)
+}
+
+// changedPaths lists the files that changed between two trees, or with added
+// only the ones new in to, as git diff --name-only does without following
+// renames. Either tree may be nil, for an empty one.
+func changedPaths(from, to *object.Tree, added bool) ([]string, error) {
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, ch := range changes {
+		switch {
+		case len(ch.From.Name) == 0:
+			paths = append(paths, ch.To.Name)
+		case !added:
+			paths = append(paths, ch.From.Name)
+		}
+	}
+
+	return paths, nil
+}
+
+// treeStats counts the lines added to and deleted from each of the files
+// under paths that changed between two trees, as git diff --numstat does.
+// Either tree may be nil, for an empty one.
+func treeStats(from, to *object.Tree, paths []string) (object.FileStats, error) {
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats object.FileStats
+	for _, ch := range changes {
+		name := ch.To.Name
+		if len(name) == 0 {
+			name = ch.From.Name
+		}
+		if !underAny(name, paths) {
+			continue
+		}
+
+		patch, err := ch.Patch()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to diff %s", name)
+		}
+		for _, stat := range patch.Stats() {
+			stat.Name = name
+			stats = append(stats, stat)
+		}
+	}
+
+	return stats, nil
+}
+
+// underAny reports whether name is one of paths or inside one of them, as git
+// pathspecs match.
+func underAny(name string, paths []string) bool {
+	for _, p := range paths {
+		if name == p || strings.HasPrefix(name, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
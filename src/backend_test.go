@@ -0,0 +1,179 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// backendFixture builds history with the cases the backends have to agree
+// on: several authors of the same file, nested directories, deletions, and a
+// merge on the base branch.
+func backendFixture(t *testing.T) *fixture {
+	f := newFixture(t)
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"main.go":     "a\nb\nc\nd\n",
+		"lib/util.go": "e\nf\n",
+	})
+	f.commit("george@git-reviewer.com", map[string]string{
+		"main.go":     "a\nB\nc\n",
+		"lib/deep.go": "g\nh\ni\n",
+	})
+	f.git("checkout", "-q", "-b", "side")
+	f.commit("tom@git-reviewer.com", map[string]string{"lib/util.go": "e\nF\nj\n"})
+	f.git("checkout", "-q", "master")
+	f.commit("abe@git-reviewer.com", map[string]string{"lib/deep.go": "g\nH\ni\nk\n"})
+	f.git("merge", "-q", "--no-ff", "-m", "Merge side", "side")
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"main.go":     "a\nB\nc\nx\n",
+		"lib/util.go": "e\nF\nj\ny\n",
+		"lib/deep.go": "g\nH\ni\nk\nz\n",
+	})
+
+	return f
+}
+
+func TestBackends(t *testing.T) {
+	f := backendFixture(t)
+	defer f.cleanup()
+
+	find := func(backend Backend, weight Weight) ([]string, Stats, []Contribution) {
+		r := f.counter()
+		r.Backend = backend
+		r.Weight = weight
+
+		files, err := r.FindFiles()
+		if err != nil {
+			t.Fatalf("Unexpected error finding files with %T: %v\n", backend, err)
+		}
+		sort.Strings(files)
+
+		stats, err := r.FindReviewerStats(files)
+		if err != nil {
+			t.Fatalf("Unexpected error finding stats with %T: %v\n", backend, err)
+		}
+
+		records, err := r.ContributionRecords(files)
+		if err != nil {
+			t.Fatalf("Unexpected error finding records with %T: %v\n", backend, err)
+		}
+
+		return files, stats, records
+	}
+
+	for _, weight := range []Weight{WeightBlame, WeightCommits} {
+		shellFiles, shellStats, shellRecords := find(ShellBackend{}, weight)
+		goFiles, goStats, goRecords := find(GoGitBackend{}, weight)
+
+		if len(shellStats) == 0 {
+			t.Fatalf("Expected the fixture to have reviewers with weight %s\n", weightNames[weight])
+		}
+		if !reflect.DeepEqual(shellFiles, goFiles) {
+			t.Errorf("Got files %v from go-git, expected %v\n", goFiles, shellFiles)
+		}
+		if !reflect.DeepEqual(shellStats, goStats) {
+			t.Errorf("Got stats %v from go-git with weight %s, expected %v\n",
+				goStats, weightNames[weight], shellStats)
+		}
+		if len(shellRecords) != len(goRecords) {
+			t.Fatalf("Got %d records from go-git, expected %d\n", len(goRecords), len(shellRecords))
+		}
+		for i := range shellRecords {
+			s, g := shellRecords[i], goRecords[i]
+			if s.SHA != g.SHA || s.Email != g.Email || s.File != g.File ||
+				s.Added != g.Added || s.Deleted != g.Deleted || !s.When.Equal(g.When) {
+				t.Errorf("Got record %+v from go-git, expected %+v\n", g, s)
+			}
+		}
+	}
+}
+
+func TestGoGitBackendWithoutGit(t *testing.T) {
+	f := backendFixture(t)
+	defer f.cleanup()
+
+	// Give every step beyond scoring something to find: an alias, a revert,
+	// CODEOWNERS, and files created on the branch
+	f.git("checkout", "-q", "master")
+	f.commitAs("abe", "abe.l@git-reviewer.com", map[string]string{"main.go": "a\nB\nC\n"})
+	f.commit("tom@git-reviewer.com", map[string]string{"lib/util.go": "e\nF\n"})
+	f.git("revert", "--no-edit", "HEAD")
+	f.commit("george@git-reviewer.com", map[string]string{".github/CODEOWNERS": "*.go @george\n"})
+	f.git("checkout", "-q", "feature")
+	f.commit("tom@git-reviewer.com", map[string]string{"lib/shared.go": "s\n"})
+	f.commit("me@git-reviewer.com", map[string]string{"lib/mine.go": "m\n"})
+
+	run := func(backend Backend, weight Weight) string {
+		r := f.counter()
+		r.Backend = backend
+		r.Weight = weight
+		r.MergeEmailPrefixes = true
+		r.NetChangesOnly = true
+		r.IgnoreImportCommit = true
+		r.ExcludeOffHours = true
+		r.WorkingHours = WorkingHours{Start: 0, End: 24}
+		r.ExcludeSelf = true
+
+		var out []string
+		add := func(v interface{}, err error) {
+			if stats, ok := v.(Stats); ok {
+				v, _ = json.Marshal(stats)
+				v = string(v.([]byte))
+			}
+			out = append(out, fmt.Sprint(v, err))
+		}
+
+		add(r.BranchBehind())
+		files, err := r.FindFiles()
+		sort.Strings(files)
+		add(files, err)
+		add(r.FindReviewers(files))
+		add(r.FindReviewersExplained(files))
+		add(r.FindReviewersForLastCommits(3))
+		add(r.FindOwners(files))
+		add(r.FindIntroducedFiles())
+		add(r.DominantExtension(files))
+		add(r.CommitHabits())
+
+		return strings.Join(out, "\n")
+	}
+
+	expected := make(map[Weight]string)
+	for _, weight := range []Weight{WeightBlame, WeightCommits} {
+		expected[weight] = run(ShellBackend{}, weight)
+	}
+
+	// Nothing may need the git binary once the fixture is built
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", "/nonexistent")
+
+	for _, weight := range []Weight{WeightBlame, WeightCommits} {
+		if out := run(GoGitBackend{}, weight); out != expected[weight] {
+			t.Errorf("Got without git with weight %s:\n%s\nexpected:\n%s",
+				weightNames[weight], out, expected[weight])
+		}
+	}
+}
+
+func TestGoGitBackendExtraLogArgs(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	r.Backend = GoGitBackend{}
+	r.ExtraLogArgs = []string{"--all"}
+
+	if _, err := r.ContributionRecords([]string{"main.go"}); err == nil {
+		t.Errorf("Expected an error using ExtraLogArgs with go-git\n")
+	}
+}
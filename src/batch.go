@@ -0,0 +1,112 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+// FindReviewersBatch finds the top reviewers for several independent groups
+// of changed paths at once, such as the change-sets CI is checking together.
+// Each file's history is only read once however many groups share it. The
+// result lists each group's reviewers in rank order, and is empty for
+// groups nobody qualifies to review.
+func (r *ContributionCounter) FindReviewersBatch(groups [][]string) ([][]string, error) {
+	defer r.startRun()()
+	return r.findBatch(groups, nil)
+}
+
+// FindReviewersBatchCapped is FindReviewersBatch for when each reviewer can
+// only take on so much: nobody is suggested for more groups than their
+// capacity allows, so once the best candidate for a file is full the next
+// best one is suggested instead. Groups are filled in order, and reviewers
+// missing from capacity have no limit.
+func (r *ContributionCounter) FindReviewersBatchCapped(groups [][]string, capacity map[string]int) ([][]string, error) {
+	defer r.startRun()()
+
+	if capacity == nil {
+		capacity = make(map[string]int)
+	}
+
+	return r.findBatch(groups, capacity)
+}
+
+// findBatch suggests reviewers for each group, only choosing from those with
+// capacity left when capacity is non-nil.
+func (r *ContributionCounter) findBatch(groups [][]string, capacity map[string]int) ([][]string, error) {
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	var all []string
+	seen := make(map[string]bool)
+	scored := make([][]string, len(groups))
+	for i, group := range groups {
+		if r.MaxDiffFiles > 0 && len(group) > r.MaxDiffFiles {
+			return nil, ErrDiffTooLarge{Files: len(group), Limit: r.MaxDiffFiles}
+		}
+
+		scored[i] = r.scoredPaths(group)
+		for _, p := range scored[i] {
+			if !seen[p] {
+				seen[p] = true
+				all = append(all, p)
+			}
+		}
+	}
+
+	all, err := r.skipToolChurn(all)
+	if err != nil {
+		return nil, err
+	}
+
+	byFile, weights, err := r.experience(all)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tool churn is judged once across the batch
+	kept := make(map[string]bool)
+	for _, p := range all {
+		kept[p] = true
+	}
+	for i, paths := range scored {
+		var left []string
+		for _, p := range paths {
+			if kept[p] {
+				left = append(left, p)
+			}
+		}
+		scored[i] = left
+	}
+
+	load := make(map[string]int)
+	batch := make([][]string, len(groups))
+	for i, paths := range scored {
+		final, err := r.score(paths, byFile, weights)
+		if _, ok := err.(NoReviewersErr); err != nil && !ok {
+			return nil, err
+		}
+
+		if capacity != nil {
+			var open Stats
+			for _, stat := range final {
+				if limit, ok := capacity[stat.Reviewer]; !ok || load[stat.Reviewer] < limit {
+					open = append(open, stat)
+				}
+			}
+			final = open
+		}
+
+		top, err := r.selectTop(final)
+		if _, ok := err.(NoReviewersErr); err != nil && !ok {
+			return nil, err
+		}
+
+		for _, stat := range top {
+			batch[i] = append(batch[i], stat.Reviewer)
+			load[stat.Reviewer]++
+		}
+	}
+
+	return batch, nil
+}
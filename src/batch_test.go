@@ -0,0 +1,116 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindReviewersBatch(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n", "shared.go": "1\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"b.go": "1\n2\n3\n", "shared.go": "1\n2\n"})
+	f.commit("me@git-reviewer.com", map[string]string{"mine.go": "1\n"})
+
+	groups := [][]string{
+		{"a.go", "shared.go"},
+		{"b.go", "shared.go"},
+		{"mine.go"},
+	}
+
+	// Log each blame to check shared files are only blamed once
+	log := filepath.Join(f.dir, ".git", "blame.log")
+	r := f.counter()
+	r.ExcludeSelf = true
+	wrapGit(r, log, `[ "$sub" = blame ] && echo "$last" >> "$0"; exec "$@"`)
+
+	batch, err := r.FindReviewersBatch(groups)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	expected := [][]string{
+		{"abe@git-reviewer.com", "george@git-reviewer.com"},
+		{"george@git-reviewer.com", "abe@git-reviewer.com"},
+		nil,
+	}
+	if !reflect.DeepEqual(batch, expected) {
+		t.Errorf("Got reviewers %v, expected %v\n", batch, expected)
+	}
+
+	// Each group gets the same answer it would on its own
+	for i, group := range groups[:2] {
+		stats, err := f.counter().FindReviewerStats(group)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+		for j, stat := range stats {
+			if stat.Reviewer != batch[i][j] {
+				t.Errorf("Got %s at %d for group %d alone, expected %s\n", stat.Reviewer, j, i, batch[i][j])
+			}
+		}
+	}
+
+	out, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatalf("Unable to read blame log: %v\n", err)
+	}
+	blamed := strings.Fields(string(out))
+	if len(blamed) != 4 {
+		t.Errorf("Blamed %v, expected each of the 4 files once\n", blamed)
+	}
+}
+
+func TestFindReviewersBatchCapped(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n", "b.go": "1\n2\n", "c.go": "1\n2\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n", "b.go": "1\n2\n3\n", "c.go": "1\n2\n3\n"})
+
+	groups := [][]string{{"a.go"}, {"b.go"}, {"c.go"}}
+
+	cases := []struct {
+		Capacity map[string]int
+		Expected [][]string
+	}{
+		{nil, [][]string{
+			{"abe@git-reviewer.com"},
+			{"abe@git-reviewer.com"},
+			{"abe@git-reviewer.com"},
+		}},
+		// Abe is full after two, so George takes the last one
+		{map[string]int{"abe@git-reviewer.com": 2}, [][]string{
+			{"abe@git-reviewer.com"},
+			{"abe@git-reviewer.com"},
+			{"george@git-reviewer.com"},
+		}},
+		{map[string]int{"abe@git-reviewer.com": 1, "george@git-reviewer.com": 1}, [][]string{
+			{"abe@git-reviewer.com"},
+			{"george@git-reviewer.com"},
+			nil,
+		}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.MaxReviewers = 1
+
+		batch, err := r.FindReviewersBatchCapped(groups, c.Capacity)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+		if !reflect.DeepEqual(batch, c.Expected) {
+			t.Errorf("Got reviewers %v with capacity %v, expected %v\n", batch, c.Capacity, c.Expected)
+		}
+	}
+}
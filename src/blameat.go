@@ -0,0 +1,68 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// BlameAt tallies who owned the lines of a file as of any revision, such as
+// a release tag, to answer who knew the code at the time rather than now.
+// Every owner is returned, most lines first and ranked as by
+// FindReviewerStats, with Since and Until applied only when they are set.
+// Exclusions, eligibility, and Veto apply as they do to suggestions. A file
+// that didn't exist at the revision is an ErrPathNotAtRevision.
+func (r *ContributionCounter) BlameAt(path, rev string) (Stats, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	h, err := r.backend().Resolve(r, rev)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to resolve revision '%s'", rev)
+	}
+
+	c, err := r.Repo.CommitObject(h)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open commit for '%s'", rev)
+	}
+	if _, err := c.File(path); err == object.ErrFileNotFound {
+		return nil, ErrPathNotAtRevision{Path: path, Rev: rev}
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "unable to open %s at '%s'", path, rev)
+	}
+
+	attributions, err := r.blame(path, h)
+	if err != nil {
+		return nil, err
+	}
+
+	final, err := r.score([]string{path}, contributions{path: attributions}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := chooseTopN(len(final), final)
+	assignRanks(owners)
+
+	return owners, nil
+}
+
+// ErrPathNotAtRevision is returned by BlameAt for a file that wasn't in the
+// tree at the revision.
+type ErrPathNotAtRevision struct {
+	Path string
+	Rev  string
+}
+
+func (e ErrPathNotAtRevision) Error() string {
+	return fmt.Sprintf("%s does not exist at %s", e.Path, e.Rev)
+}
@@ -0,0 +1,58 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+)
+
+func TestBlameAt(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n"})
+	f.git("tag", "-a", "-m", "First release", "v1.0")
+	f.commit("tom@git-reviewer.com", map[string]string{"a.go": "x\ny\nz\n4\n5\n", "b.go": "b\n"})
+
+	cases := []struct {
+		Rev      string
+		Expected []string
+		Counts   []int64
+	}{
+		{"v1.0", []string{"abe@git-reviewer.com", "george@git-reviewer.com"}, []int64{4, 1}},
+		{"HEAD", []string{"tom@git-reviewer.com", "abe@git-reviewer.com", "george@git-reviewer.com"}, []int64{3, 1, 1}},
+	}
+
+	for _, c := range cases {
+		stats, err := f.counter().BlameAt("a.go", c.Rev)
+		if err != nil {
+			t.Fatalf("Unexpected error blaming at %s: %v\n", c.Rev, err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Fatalf("Got %v at %s, expected %v\n", stats, c.Rev, c.Expected)
+		}
+		for i, stat := range stats {
+			// Ties are broken by RandSeed, so only the leader's place is fixed
+			if i == 0 && stat.Reviewer != c.Expected[0] {
+				t.Errorf("Got %s first at %s, expected %s\n", stat.Reviewer, c.Rev, c.Expected[0])
+			}
+			if stat.Count != c.Counts[i] {
+				t.Errorf("Got %d lines for %s at %s, expected %d\n", stat.Count, stat.Reviewer, c.Rev, c.Counts[i])
+			}
+		}
+	}
+
+	_, err := f.counter().BlameAt("b.go", "v1.0")
+	if e, ok := err.(ErrPathNotAtRevision); !ok || e.Path != "b.go" || e.Rev != "v1.0" {
+		t.Errorf("Got error %v blaming a file added later, expected ErrPathNotAtRevision\n", err)
+	}
+
+	if _, err := f.counter().BlameAt("a.go", "v9.9"); err == nil {
+		t.Error("Expected an error blaming at an unknown revision")
+	}
+}
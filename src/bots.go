@@ -0,0 +1,96 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultIgnoredAuthorPatterns are the IgnoredAuthorPatterns used when none
+// are given: DefaultToolAuthors as regular expressions, so the same
+// identities count as bots whether tool churn is being skipped or reviewers
+// suggested.
+var DefaultIgnoredAuthorPatterns = globPatterns(DefaultToolAuthors)
+
+// globPatterns turns path.Match globs into regular expressions matching the
+// same whole strings.
+func globPatterns(globs []string) []string {
+	patterns := make([]string, len(globs))
+	for i, glob := range globs {
+		var rx strings.Builder
+		rx.WriteString("^")
+		for j := 0; j < len(glob); j++ {
+			switch c := glob[j]; {
+			case c == '*':
+				rx.WriteString("[^/]*")
+			case c == '?':
+				rx.WriteString("[^/]")
+			case c == '\\' && j+1 < len(glob):
+				j++
+				rx.WriteString(regexp.QuoteMeta(glob[j : j+1]))
+			case c == '[':
+				// Classes read the same either way, up to their closing bracket
+				end := strings.IndexByte(glob[j+1:], ']')
+				if end < 0 {
+					rx.WriteString(`\[`)
+					continue
+				}
+				rx.WriteString(glob[j : j+end+2])
+				j += end + 1
+			default:
+				rx.WriteString(regexp.QuoteMeta(glob[j : j+1]))
+			}
+		}
+		rx.WriteString("$")
+		patterns[i] = rx.String()
+	}
+
+	return patterns
+}
+
+// ignoredAuthorPatterns returns the patterns recognizing bots.
+func (r *ContributionCounter) ignoredAuthorPatterns() []string {
+	if len(r.IgnoredAuthorPatterns) > 0 {
+		return r.IgnoredAuthorPatterns
+	}
+
+	return DefaultIgnoredAuthorPatterns
+}
+
+// botPatterns compiles the patterns recognizing bots, once per run rather
+// than for every candidate. There are none with IncludeBots.
+func (r *ContributionCounter) botPatterns() ([]*regexp.Regexp, error) {
+	if r.IncludeBots {
+		return nil, nil
+	}
+
+	patterns := r.ignoredAuthorPatterns()
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		rx, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid author pattern %q", pattern)
+		}
+		compiled[i] = rx
+	}
+
+	return compiled, nil
+}
+
+// isBot reports whether the Stat's email or name matches one of the bot
+// patterns.
+func isBot(patterns []*regexp.Regexp, stat *Stat) bool {
+	for _, rx := range patterns {
+		if rx.MatchString(stat.Reviewer) || (len(stat.Name) > 0 && rx.MatchString(stat.Name)) {
+			return true
+		}
+	}
+
+	return false
+}
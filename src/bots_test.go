@@ -0,0 +1,89 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIgnoreBots(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	dependabot := "49699333+dependabot[bot]@users.noreply.github.com"
+	f.commitAs("dependabot[bot]", dependabot, map[string]string{"go.sum": "a\nb\nc\nd\n"})
+	f.commitAs("Jenkins", "ci@git-reviewer.com", map[string]string{"VERSION": "1\n2\n3\n"})
+	f.commitAs("Abe", "123+abe@users.noreply.github.com", map[string]string{"main.go": "x\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"go.sum": "a\nb\nc\nd\ne\n", "VERSION": "1\n2\n3\n4\n", "main.go": "x\ny\n",
+	})
+
+	cases := []struct {
+		IncludeBots bool
+		Patterns    []string
+		Expected    []string
+		Absent      []string
+	}{
+		{false, nil, []string{"ci@git-reviewer.com", "123+abe@users.noreply.github.com"}, []string{dependabot}},
+		{true, nil, []string{dependabot, "ci@git-reviewer.com", "123+abe@users.noreply.github.com"}, nil},
+		{false, []string{`^jenkins$`, `\[bot\]`}, []string{"123+abe@users.noreply.github.com"}, []string{dependabot, "ci@git-reviewer.com"}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.IncludeBots = c.IncludeBots
+		r.IgnoredAuthorPatterns = c.Patterns
+
+		out := f.reviewers(r)
+		for _, email := range c.Expected {
+			if !strings.Contains(out, email) {
+				t.Errorf("Expected %s among reviewers with patterns %v, got:\n%s", email, c.Patterns, out)
+			}
+		}
+		for _, email := range c.Absent {
+			if strings.Contains(out, email) {
+				t.Errorf("Expected %s to be left out with patterns %v, got:\n%s", email, c.Patterns, out)
+			}
+		}
+	}
+
+	r := f.counter()
+	r.IgnoredAuthorPatterns = []string{"("}
+	if _, err := r.FindReviewerStats([]string{"main.go"}); err == nil {
+		t.Errorf("Expected an error for an invalid author pattern\n")
+	}
+}
+
+func TestBotsAreTools(t *testing.T) {
+	r := &ContributionCounter{}
+	bots, err := r.botPatterns()
+	if err != nil {
+		t.Fatalf("Unexpected error compiling default bot patterns: %v\n", err)
+	}
+
+	cases := []struct {
+		Name, Email string
+		Bot         bool
+	}{
+		{"dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", true},
+		{"Deploy", "deploy-bot@git-reviewer.com", true},
+		{"release-bot", "release@git-reviewer.com", true},
+		{"Build", "bot@git-reviewer.com", true},
+		{"gofmt", "ci@git-reviewer.com", true},
+		{"GitHub", "noreply@github.com", true},
+		{"Abe", "123+abe@users.noreply.github.com", false},
+		{"Abbot", "abbot@git-reviewer.com", false},
+	}
+
+	for _, c := range cases {
+		stat := &Stat{Reviewer: c.Email, Name: c.Name}
+		if bot, tool := isBot(bots, stat), isTool(r.toolAuthors(), c.Name, c.Email); bot != c.Bot || tool != c.Bot {
+			t.Errorf("Got bot %v and tool %v for %s <%s>, expected %v\n", bot, tool, c.Name, c.Email, c.Bot)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"sort"
+)
+
+// coChangeThreshold is how similar the sets of commits touching two files must
+// be, by jaccard, for the files to be clustered together.
+const coChangeThreshold = 0.5
+
+// CoChangeClusters groups the paths by co-change history: files that have
+// mostly been changed in the same commits since Since belong together, and so
+// do files linked through a chain of such pairs. Files without history are
+// clusters of their own. Clusters and the paths in them are sorted.
+func (r *ContributionCounter) CoChangeClusters(paths []string) ([][]string, error) {
+	defer r.startRun()()
+
+	records, err := r.ContributionRecords(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make(map[string]map[string]bool)
+	for _, rec := range records {
+		if commits[rec.File] == nil {
+			commits[rec.File] = make(map[string]bool)
+		}
+		commits[rec.File][rec.SHA] = true
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+
+	// Union-find over the co-change matrix
+	parent := make([]int, len(files))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range files {
+		for j := i + 1; j < len(files); j++ {
+			if jaccard(commits[files[i]], commits[files[j]]) >= coChangeThreshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	var clusters [][]string
+	index := make(map[int]int)
+	for i, file := range files {
+		root := find(i)
+		c, ok := index[root]
+		if !ok {
+			c = len(clusters)
+			index[root] = c
+			clusters = append(clusters, nil)
+		}
+		clusters[c] = append(clusters[c], file)
+	}
+
+	return clusters, nil
+}
+
+// FindReviewersByCluster splits a large change into CoChangeClusters and finds
+// the top reviewers for each separately, since files that change together
+// tend to share experts. Results are keyed by the cluster's index in
+// CoChangeClusters. Clusters where nobody qualifies have no Stats.
+func (r *ContributionCounter) FindReviewersByCluster(paths []string) (map[int]Stats, error) {
+	defer r.startRun()()
+
+	clusters, err := r.CoChangeClusters(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	byCluster := make(map[int]Stats)
+	for i, files := range clusters {
+		stats, err := r.FindReviewerStats(files)
+		if _, ok := err.(NoReviewersErr); err != nil && !ok {
+			return nil, err
+		}
+
+		byCluster[i] = stats
+	}
+
+	return byCluster, nil
+}
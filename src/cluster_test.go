@@ -0,0 +1,46 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindReviewersByCluster(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// The API handler and its client always change together, and the docs
+	// change on their own
+	f.commit("abe@git-reviewer.com", map[string]string{"api/handler.go": "1\n", "web/client.js": "1\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"api/handler.go": "1\n2\n", "web/client.js": "1\n2\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"docs/guide.md": "1\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"docs/guide.md": "1\n2\n", "api/handler.go": "1\n2\n3\n"})
+
+	paths := []string{"web/client.js", "docs/guide.md", "api/handler.go"}
+	r := f.counter()
+
+	clusters, err := r.CoChangeClusters(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error clustering: %v\n", err)
+	}
+	expected := [][]string{{"api/handler.go", "web/client.js"}, {"docs/guide.md"}}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Fatalf("Got clusters %v, expected %v\n", clusters, expected)
+	}
+
+	byCluster, err := r.FindReviewersByCluster(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if stats := byCluster[0]; len(stats) == 0 || stats[0].Reviewer != "abe@git-reviewer.com" {
+		t.Errorf("Got reviewers %v for the API cluster, expected abe first\n", stats)
+	}
+	if stats := byCluster[1]; len(stats) != 1 || stats[0].Reviewer != "george@git-reviewer.com" {
+		t.Errorf("Got reviewers %v for the docs cluster, expected only george\n", stats)
+	}
+}
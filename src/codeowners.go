@@ -0,0 +1,405 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// codeOwnersLocations are where GitHub looks for a CODEOWNERS file, in the
+// order it looks.
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// ownerRule is one line of a CODEOWNERS file.
+type ownerRule struct {
+	segments []string
+	anchored bool
+	dirOnly  bool
+	owners   []string
+}
+
+// FindOwners reads the CODEOWNERS file as of the base revision, like GitHub
+// does for pull requests, and returns the owners of each path. As on GitHub
+// the last matching rule wins, and a rule for a directory applies to
+// everything under it, so a file no rule names is owned by whoever owns its
+// nearest covered parent directory. Paths without owners are left out, and
+// so is everything when there is no CODEOWNERS file.
+func (r *ContributionCounter) FindOwners(paths []string) (map[string][]string, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []ownerRule
+	for _, loc := range codeOwnersLocations {
+		out, err := r.fileAt(base, loc)
+		if err == nil {
+			rules = parseCodeOwners(out)
+			break
+		}
+	}
+
+	owners := make(map[string][]string)
+	for _, p := range paths {
+		if o := ownersOf(rules, p); len(o) > 0 {
+			owners[p] = o
+		}
+	}
+
+	return owners, nil
+}
+
+// fileAt reads the contents of the file at p as of commit c.
+func (r *ContributionCounter) fileAt(c *object.Commit, p string) ([]byte, error) {
+	if r.runsGit() {
+		return r.git("show", c.Hash.String()+":"+p)
+	}
+
+	f, err := c.File(p)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := f.Contents()
+	return []byte(contents), err
+}
+
+// parseCodeOwners reads the rules out of a CODEOWNERS file, skipping blank
+// lines and comments.
+func parseCodeOwners(data []byte) []ownerRule {
+	var rules []ownerRule
+
+	scn := bufio.NewScanner(bytes.NewReader(data))
+	for scn.Scan() {
+		fields := codeOwnersFields(scn.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule := parsePattern(fields[0])
+		rule.owners = fields[1:]
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// codeOwnersFields splits a CODEOWNERS line into its pattern and owners,
+// dropping any comment. A backslash keeps a space or "#" in the pattern, as
+// writeOwnerLine escapes them; other escapes are left for path.Match.
+func codeOwnersFields(line string) []string {
+	var (
+		fields []string
+		field  strings.Builder
+		open   bool
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			if next := line[i]; next != ' ' && next != '\t' && next != '#' {
+				field.WriteByte(c)
+			}
+			field.WriteByte(line[i])
+			open = true
+		case c == '#':
+			i = len(line)
+		case c == ' ' || c == '\t':
+			if open {
+				fields = append(fields, field.String())
+				field.Reset()
+				open = false
+			}
+		default:
+			field.WriteByte(c)
+			open = true
+		}
+	}
+	if open {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
+
+// parsePattern reads a CODEOWNERS path pattern into a rule without owners.
+func parsePattern(pattern string) ownerRule {
+	// Like gitignore, a slash anywhere but the end ties the pattern to the
+	// repository root; otherwise it may match at any depth.
+	var rule ownerRule
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	rule.anchored = strings.Contains(pattern, "/")
+	rule.segments = strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+
+	return rule
+}
+
+// ownersOf applies rules to a single path. A matching rule without owners
+// leaves the path unowned.
+func ownersOf(rules []ownerRule, p string) []string {
+	var owners []string
+	for _, rule := range rules {
+		if rule.matches(p) {
+			owners = rule.owners
+		}
+	}
+
+	return owners
+}
+
+// matches reports whether the rule covers a path, either naming it directly
+// or naming one of the directories it is in.
+func (rule ownerRule) matches(p string) bool {
+	segs := strings.Split(p, "/")
+
+	// Directory-only rules can't name the file itself, only what it's in.
+	// Rules ending in "/*" name only what is directly in that directory,
+	// which GitHub doesn't extend to nested directories.
+	first, last := 1, len(segs)
+	if rule.dirOnly {
+		last--
+	}
+	if rule.anchored && rule.segments[len(rule.segments)-1] == "*" {
+		first = last
+	}
+
+	for end := first; end <= last; end++ {
+		if rule.anchored {
+			if globSegments(rule.segments, segs[:end]) {
+				return true
+			}
+		} else if globSegments(rule.segments, segs[end-1:end]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// globSegments matches path segments against pattern segments, where "**"
+// stands for any number of segments and anything else is a path.Match glob.
+func globSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if globSegments(pattern[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segs[0]); !ok || err != nil {
+		return false
+	}
+
+	return globSegments(pattern[1:], segs[1:])
+}
+
+// OwnershipAgreement checks how well history agrees with declared ownership:
+// it is the fraction of the suggested reviewers for the paths who are also
+// CODEOWNERS of at least one of them. Reviewers match owners listed by email,
+// or by the handle Handles resolves for them when it is set. Owners that are
+// teams never match, since their members aren't known. With nobody
+// suggested, the agreement is 0.
+func (r *ContributionCounter) OwnershipAgreement(paths []string) (float64, error) {
+	defer r.startRun()()
+
+	suggested, err := r.FindReviewerStats(paths)
+	if err != nil {
+		return 0, err
+	}
+
+	owners, err := r.FindOwners(paths)
+	if err != nil {
+		return 0, err
+	}
+
+	declared := declaredOwners(owners)
+
+	agreed := 0
+	for _, stat := range suggested {
+		if r.isDeclaredOwner(declared, stat.Reviewer) {
+			agreed++
+		}
+	}
+
+	if len(suggested) == 0 {
+		return 0, nil
+	}
+
+	return float64(agreed) / float64(len(suggested)), nil
+}
+
+// GenerateCodeowners writes a CODEOWNERS file to w giving each of the paths
+// to the owners reviewers with the most experience in it, for bootstrapping
+// one from history. Owners are ranked as by FindReviewerStats, but for each
+// path on its own, and written as the handles that handles maps their
+// emails to, or as their emails when it has none. A directory whose files at
+// the base are all among the paths, and all have the same owners, gets one
+// "/dir/*" line rather than a line per file; otherwise that rule would also
+// assign the files left out. Zero owners means as many as FindReviewerStats
+// suggests, and paths nobody qualifies for are left out.
+func (r *ContributionCounter) GenerateCodeowners(paths []string, w io.Writer, handles map[string]string, owners int) error {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return err
+	}
+	if owners <= 0 {
+		owners = r.reviewerLimit()
+	}
+
+	paths = r.scoredPaths(paths)
+
+	base, err := r.baseCommit()
+	if err != nil {
+		return err
+	}
+	baseTree, err := base.Tree()
+	if err != nil {
+		return errors.Wrap(err, "unable to open tree at base")
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	byDir := make(map[string][]string)
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			dir := path.Dir(p)
+			byDir[dir] = append(byDir[dir], p)
+			unique = append(unique, p)
+		}
+	}
+
+	byFile, weights, err := r.experience(unique)
+	if err != nil {
+		return err
+	}
+
+	ownersFor := func(files []string) ([]string, error) {
+		final, err := r.score(files, byFile, weights)
+		if _, ok := err.(allExcludedErr); ok {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		var list []string
+		for _, stat := range r.rank(owners, final) {
+			list = append(list, codeOwner(stat.Reviewer, handles))
+		}
+		return list, nil
+	}
+
+	var dirs []string
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Generated by git-reviewer from history since %s\n", r.since())
+	for _, dir := range dirs {
+		files := byDir[dir]
+		sort.Strings(files)
+
+		perFile := make([][]string, len(files))
+		same := dir != "."
+		for i, p := range files {
+			if perFile[i], err = ownersFor([]string{p}); err != nil {
+				return err
+			}
+			same = same && strings.Join(perFile[i], " ") == strings.Join(perFile[0], " ")
+		}
+
+		if same && len(files) > 1 {
+			if same, err = wholeDir(baseTree, dir, files); err != nil {
+				return err
+			}
+		}
+		if same && len(files) > 1 {
+			writeOwnerLine(bw, dir+"/*", perFile[0])
+			continue
+		}
+		for i, p := range files {
+			writeOwnerLine(bw, p, perFile[i])
+		}
+	}
+
+	return errors.Wrap(bw.Flush(), "unable to write CODEOWNERS")
+}
+
+// wholeDir reports whether files, all directly in dir, are every file
+// directly in dir in the tree.
+func wholeDir(tree *object.Tree, dir string, files []string) (bool, error) {
+	sub, err := tree.Tree(dir)
+	if err == object.ErrDirectoryNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "unable to open %s at base", dir)
+	}
+
+	listed := make(map[string]bool, len(files))
+	for _, p := range files {
+		listed[path.Base(p)] = true
+	}
+	for _, entry := range sub.Entries {
+		if entry.Mode.IsFile() && !listed[entry.Name] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// codeOwner names a reviewer as a CODEOWNERS owner: by their handle, with
+// the "@" GitHub expects, or by email.
+func codeOwner(reviewer string, handles map[string]string) string {
+	if handle, ok := handles[reviewer]; ok && len(strings.TrimPrefix(handle, "@")) > 0 {
+		return "@" + strings.TrimPrefix(handle, "@")
+	}
+
+	return reviewer
+}
+
+// writeOwnerLine writes one rule anchored at the repository root, escaping
+// what would otherwise end the pattern or start a comment. Patterns without
+// owners aren't written, since they would disown the path.
+func writeOwnerLine(w io.Writer, pattern string, owners []string) {
+	if len(owners) == 0 {
+		return
+	}
+
+	escaped := strings.NewReplacer(" ", `\ `, "#", `\#`).Replace(pattern)
+	fmt.Fprintf(w, "/%s %s\n", escaped, strings.Join(owners, " "))
+}
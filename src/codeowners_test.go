@@ -0,0 +1,224 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFindOwners(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{
+		".github/CODEOWNERS": "# Default owners\n" +
+			"*                 @org/everyone\n" +
+			"*.js              @js-owner\n" +
+			"/src/             @src-owner\n" +
+			"/src/api/         @api-owner  # more specific, so listed later\n" +
+			"/src/api/gen/\n" +
+			"docs/*            docs@git-reviewer.com\n" +
+			"build/            @builder\n" +
+			"/vendor/**/LICENSE @legal\n",
+		"main.go": "1\n",
+	})
+	f.commit("me@git-reviewer.com", map[string]string{
+		"CODEOWNERS": "* @ignored\n",
+	})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		".github/CODEOWNERS": "* @from-the-branch\n",
+	})
+
+	cases := []struct {
+		Path   string
+		Owners []string
+	}{
+		{"main.go", []string{"@org/everyone"}},
+		{"app.js", []string{"@js-owner"}},
+		// Only covered by rules for a parent directory
+		{"src/util/strings.go", []string{"@src-owner"}},
+		{"src/api/v1/handlers.go", []string{"@api-owner"}},
+		// The last matching rule wins, even over a more specific pattern
+		{"src/web/app.js", []string{"@src-owner"}},
+		{"docs/index.md", []string{"docs@git-reviewer.com"}},
+		{"docs/guides/setup.md", []string{"@org/everyone"}},
+		{"tools/build/Makefile", []string{"@builder"}},
+		{"vendor/a/b/LICENSE", []string{"@legal"}},
+		// An ownerless rule leaves its files unowned
+		{"src/api/gen/types.go", nil},
+	}
+
+	var paths []string
+	for _, c := range cases {
+		paths = append(paths, c.Path)
+	}
+
+	owners, err := f.counter().FindOwners(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error finding owners: %v\n", err)
+	}
+
+	for _, c := range cases {
+		if actual := owners[c.Path]; !reflect.DeepEqual(actual, c.Owners) {
+			t.Errorf("Got owners %v for %s, expected %v\n", actual, c.Path, c.Owners)
+		}
+	}
+}
+
+func TestFindOwnersWithoutCodeOwners(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	owners, err := f.counter().FindOwners([]string{"main.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding owners: %v\n", err)
+	}
+	if len(owners) != 0 {
+		t.Errorf("Got owners %v, expected none without a CODEOWNERS file\n", owners)
+	}
+}
+
+func TestOwnershipAgreement(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"api/a.go": "1\n2\n3\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"web/b.go": "1\n2\n"})
+
+	handles := map[string]string{"george@git-reviewer.com": "george-gh"}
+	resolve := func(email string) (string, bool) {
+		h, ok := handles[email]
+		return h, ok
+	}
+
+	cases := []struct {
+		CodeOwners string
+		Excluded   []string
+		Expected   float64
+	}{
+		{"/api/ abe@git-reviewer.com\n/web/ @george-gh\n", nil, 1},
+		{"/api/ ABE@git-reviewer.com\n/web/ @org/web-team\n", nil, 0.5},
+		{"* @someone-else\n", nil, 0},
+		{"* @someone-else\n", []string{"abe", "george"}, 0},
+	}
+
+	for _, c := range cases {
+		f.commit("me@git-reviewer.com", map[string]string{"CODEOWNERS": c.CodeOwners})
+
+		r := f.counter()
+		r.Handles = resolve
+		r.ExcludedReviewers = c.Excluded
+		actual, err := r.OwnershipAgreement([]string{"api/a.go", "web/b.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error checking agreement: %v\n", err)
+		}
+
+		if actual != c.Expected {
+			t.Errorf("Got agreement %f with CODEOWNERS:\n%s\nexpected %f\n", actual, c.CodeOwners, c.Expected)
+		}
+	}
+}
+
+func TestGenerateCodeowners(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// abe wrote all of api/, while web/ is split between george and tom
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"api/a.go": "1\n2\n", "api/b.go": "1\n", "README.md": "hi\n",
+	})
+	f.commit("george@git-reviewer.com", map[string]string{"web/x.js": "1\n2\n3\n", "api/a.go": "1\n2\n3\n"})
+	f.commit("tom@git-reviewer.com", map[string]string{"web/my page.js": "1\n"})
+
+	paths := []string{"web/x.js", "api/a.go", "README.md", "api/b.go", "web/my page.js"}
+	handles := map[string]string{"abe@git-reviewer.com": "abe-gh", "george@git-reviewer.com": "@george-gh"}
+
+	var buf bytes.Buffer
+	r := f.counter()
+	if err := r.GenerateCodeowners(paths, &buf, handles, 1); err != nil {
+		t.Fatalf("Unexpected error generating CODEOWNERS: %v\n", err)
+	}
+
+	expected := "# Generated by git-reviewer from history since " + r.since() + "\n" +
+		"/README.md @abe-gh\n" +
+		"/api/* @abe-gh\n" +
+		"/web/my\\ page.js tom@git-reviewer.com\n" +
+		"/web/x.js @george-gh\n"
+	if buf.String() != expected {
+		t.Errorf("Got CODEOWNERS:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	// Every rule is a pattern followed by handles or emails
+	rx := regexp.MustCompile(`^/(\\.|[^\s\\#])+( (@[\w.-]+(/[\w.-]+)?|[^\s@]+@[^\s@]+))+$`)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.HasPrefix(line, "#") && !rx.MatchString(line) {
+			t.Errorf("Got invalid CODEOWNERS line '%s'\n", line)
+		}
+	}
+
+	// The file assigns what it was generated from
+	rules := parseCodeOwners(buf.Bytes())
+	for p, owner := range map[string]string{"api/a.go": "@abe-gh", "api/b.go": "@abe-gh", "web/x.js": "@george-gh"} {
+		if actual := ownersOf(rules, p); !reflect.DeepEqual(actual, []string{owner}) {
+			t.Errorf("Got owners %v for %s, expected %s\n", actual, p, owner)
+		}
+	}
+
+	// With more owners per path, api/ files no longer agree
+	buf.Reset()
+	if err := f.counter().GenerateCodeowners([]string{"api/a.go", "api/b.go"}, &buf, handles, 2); err != nil {
+		t.Fatalf("Unexpected error generating CODEOWNERS: %v\n", err)
+	}
+	if !strings.Contains(buf.String(), "/api/a.go @abe-gh @george-gh\n/api/b.go @abe-gh\n") {
+		t.Errorf("Got CODEOWNERS:\n%s\nexpected a line per file\n", buf.String())
+	}
+
+	// Once api/ has a file that wasn't scored, "/api/*" would assign it too
+	f.commit("tom@git-reviewer.com", map[string]string{"api/c.go": "1\n"})
+	buf.Reset()
+	if err := f.counter().GenerateCodeowners([]string{"api/a.go", "api/b.go"}, &buf, handles, 1); err != nil {
+		t.Fatalf("Unexpected error generating CODEOWNERS: %v\n", err)
+	}
+	if !strings.Contains(buf.String(), "/api/a.go @abe-gh\n/api/b.go @abe-gh\n") {
+		t.Errorf("Got CODEOWNERS:\n%s\nexpected a line per file\n", buf.String())
+	}
+}
+
+func TestGenerateCodeownersRoundTrip(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"web/my page.js": "1\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"web/#1.js": "1\n"})
+	f.commit("tom@git-reviewer.com", map[string]string{"web/x.js": "1\n"})
+
+	paths := []string{"web/my page.js", "web/#1.js", "web/x.js"}
+	var buf bytes.Buffer
+	if err := f.counter().GenerateCodeowners(paths, &buf, nil, 1); err != nil {
+		t.Fatalf("Unexpected error generating CODEOWNERS: %v\n", err)
+	}
+
+	// The generated file assigns every path it was generated from
+	f.commit("me@git-reviewer.com", map[string]string{"CODEOWNERS": buf.String()})
+	owners, err := f.counter().FindOwners(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error finding owners: %v\n", err)
+	}
+
+	expected := map[string][]string{
+		"web/my page.js": {"abe@git-reviewer.com"},
+		"web/#1.js":      {"george@git-reviewer.com"},
+		"web/x.js":       {"tom@git-reviewer.com"},
+	}
+	if !reflect.DeepEqual(owners, expected) {
+		t.Errorf("Got owners %v from CODEOWNERS:\n%s\nexpected %v\n", owners, buf.String(), expected)
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"encoding/json"
+)
+
+// effectiveConfig is the document EffectiveConfig writes and LoadConfig
+// reads.
+type effectiveConfig struct {
+	WorkDir               string   `json:"workDir,omitempty"`
+	BaseBranch            string   `json:"baseBranch,omitempty"`
+	Since                 string   `json:"since,omitempty"`
+	Until                 string   `json:"until,omitempty"`
+	TimeZone              string   `json:"timeZone,omitempty"`
+	IgnoredExtensions     []string `json:"ignoredExtensions"`
+	OnlyExtensions        []string `json:"onlyExtensions"`
+	IgnoredPaths          []string `json:"ignoredPaths"`
+	OnlyPaths             []string `json:"onlyPaths"`
+	OnlyLanguages         []string `json:"onlyLanguages"`
+	NoScorePaths          []string `json:"noScorePaths"`
+	CriticalPaths         []string `json:"criticalPaths"`
+	SkipToolChurn         bool     `json:"skipToolChurn"`
+	ToolAuthors           []string `json:"toolAuthors"`
+	Reviewers             int      `json:"reviewers,omitempty"`
+	StrictMaxReviewers    bool     `json:"strictMaxReviewers"`
+	ExcludeSelf           bool     `json:"excludeSelf"`
+	SelfIdentity          string   `json:"selfIdentity,omitempty"`
+	ExcludedReviewers     []string `json:"excludedReviewers"`
+	IncludeBots           bool     `json:"includeBots"`
+	IgnoredAuthorPatterns []string `json:"ignoredAuthorPatterns"`
+	MergeEmailPrefixes    bool     `json:"mergeEmailPrefixes"`
+	IgnoreMailmap         bool     `json:"ignoreMailmap"`
+	ExcludeOffHours       bool     `json:"excludeOffHours"`
+	WorkingHours          string   `json:"workingHours"`
+	MinCommits            int      `json:"minCommits"`
+	MinDistinctReviewers  int      `json:"minDistinctReviewers"`
+	PreferFastReviewers   bool     `json:"preferFastReviewers"`
+	PreferWorkingHours    bool     `json:"preferWorkingHours"`
+	Backend               string   `json:"backend"`
+	Weight                string   `json:"weight"`
+	SquashConsecutive     bool     `json:"squashConsecutive"`
+	Aggregation           string   `json:"aggregation"`
+	NetChangesOnly        bool     `json:"netChangesOnly"`
+	IgnoreImportCommit    bool     `json:"ignoreImportCommit"`
+	DetectCopies          bool     `json:"detectCopies"`
+	ActivityWeight        bool     `json:"activityWeight"`
+	DependencyOrder       bool     `json:"dependencyOrder"`
+	MaxDiffFiles          int      `json:"maxDiffFiles"`
+	ScorePrecision        int      `json:"scorePrecision,omitempty"`
+	MaxWidth              int      `json:"maxWidth"`
+	Concurrency           int      `json:"concurrency,omitempty"`
+	RandSeed              int64    `json:"randSeed"`
+	CommandPrefix         []string `json:"commandPrefix"`
+	RateLimit             float64  `json:"rateLimit"`
+	ExtraLogArgs          []string `json:"extraLogArgs"`
+	Hooks                 []string `json:"hooks"`
+
+	DirDependencies map[string][]string `json:"dirDependencies,omitempty"`
+}
+
+var weightNames = map[Weight]string{
+	WeightBlame:   "blame",
+	WeightCommits: "commits",
+	WeightLines:   "lines",
+}
+
+var aggregationNames = map[Aggregation]string{
+	AggregateSum:  "sum",
+	AggregateMax:  "max",
+	AggregateMean: "mean",
+}
+
+// backendName names the built in backends, and calls any other "custom".
+func backendName(b Backend) string {
+	switch b.(type) {
+	case ShellBackend, *ShellBackend:
+		return "shell"
+	case GoGitBackend, *GoGitBackend:
+		return "go-git"
+	}
+
+	return "custom"
+}
+
+// EffectiveConfig describes the settings the counter finds reviewers with as
+// JSON, in the form LoadConfig reads, so a run can be reproduced. Options
+// that were left unset are left out or empty rather than filled in with
+// their defaults, which may depend on the run: the default since moves
+// forward every day, the base follows origin/HEAD, and concurrency follows
+// the machine. Loading the output therefore gives back the same options.
+// Callbacks and other values that can't be written out are only listed by
+// name under hooks when they are set.
+func (r *ContributionCounter) EffectiveConfig() ([]byte, error) {
+	if r == nil {
+		return nil, ErrNilCounter
+	}
+	defer r.startRun()()
+
+	return json.MarshalIndent(r.describeConfig(), "", "  ")
+}
+
+// describeConfig gathers the options EffectiveConfig writes.
+func (r *ContributionCounter) describeConfig() effectiveConfig {
+	cfg := effectiveConfig{
+		WorkDir:               r.WorkDir,
+		BaseBranch:            r.BaseBranch,
+		Since:                 r.Since,
+		Until:                 r.Until,
+		TimeZone:              r.timeZoneName(),
+		IgnoredExtensions:     nonNil(r.IgnoredExtensions),
+		OnlyExtensions:        nonNil(r.OnlyExtensions),
+		IgnoredPaths:          nonNil(r.IgnoredPaths),
+		OnlyPaths:             nonNil(r.OnlyPaths),
+		OnlyLanguages:         nonNil(r.OnlyLanguages),
+		NoScorePaths:          nonNil(r.NoScorePaths),
+		CriticalPaths:         nonNil(r.CriticalPaths),
+		SkipToolChurn:         r.SkipToolChurn,
+		ToolAuthors:           nonNil(r.ToolAuthors),
+		Reviewers:             r.MaxReviewers,
+		StrictMaxReviewers:    r.StrictMaxReviewers,
+		ExcludeSelf:           r.ExcludeSelf,
+		SelfIdentity:          r.SelfIdentity,
+		ExcludedReviewers:     nonNil(r.ExcludedReviewers),
+		IncludeBots:           r.IncludeBots,
+		IgnoredAuthorPatterns: nonNil(r.IgnoredAuthorPatterns),
+		MergeEmailPrefixes:    r.MergeEmailPrefixes,
+		IgnoreMailmap:         r.IgnoreMailmap,
+		ExcludeOffHours:       r.ExcludeOffHours,
+		WorkingHours:          r.WorkingHours.String(),
+		MinCommits:            r.MinCommits,
+		MinDistinctReviewers:  r.MinDistinctReviewers,
+		PreferFastReviewers:   r.PreferFastReviewers,
+		PreferWorkingHours:    r.PreferWorkingHours,
+		Backend:               backendName(r.backend()),
+		Weight:                weightNames[r.Weight],
+		SquashConsecutive:     r.SquashConsecutive,
+		Aggregation:           aggregationNames[r.Aggregation],
+		NetChangesOnly:        r.NetChangesOnly,
+		IgnoreImportCommit:    r.IgnoreImportCommit,
+		DetectCopies:          r.DetectCopies,
+		ActivityWeight:        r.ActivityWeight,
+		DependencyOrder:       r.DependencyOrder,
+		MaxDiffFiles:          r.MaxDiffFiles,
+		ScorePrecision:        r.ScorePrecision,
+		MaxWidth:              r.MaxWidth,
+		Concurrency:           r.Concurrency,
+		RandSeed:              r.RandSeed,
+		CommandPrefix:         nonNil(r.CommandPrefix),
+		RateLimit:             r.RateLimit,
+		ExtraLogArgs:          nonNil(r.ExtraLogArgs),
+		Hooks:                 []string{},
+		DirDependencies:       r.DirDependencies,
+	}
+
+	if r.LatencyProvider != nil {
+		cfg.Hooks = append(cfg.Hooks, "LatencyProvider")
+	}
+	if r.Eligibility != nil {
+		cfg.Hooks = append(cfg.Hooks, "Eligibility")
+	}
+	if r.Handles != nil {
+		cfg.Hooks = append(cfg.Hooks, "Handles")
+	}
+	if r.OnUnrelatedBase != nil {
+		cfg.Hooks = append(cfg.Hooks, "OnUnrelatedBase")
+	}
+	if r.Veto != nil {
+		cfg.Hooks = append(cfg.Hooks, "Veto")
+	}
+	if r.PostProcess != nil {
+		cfg.Hooks = append(cfg.Hooks, "PostProcess")
+	}
+	if r.StateStore != nil {
+		cfg.Hooks = append(cfg.Hooks, "StateStore")
+	}
+
+	return cfg
+}
+
+// nonNil keeps empty lists from being written as null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
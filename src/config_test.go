@@ -0,0 +1,82 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	cases := []struct {
+		Counter  *ContributionCounter
+		Expected map[string]interface{}
+	}{
+		// Defaults are left out, so loading the config keeps them
+		{&ContributionCounter{}, map[string]interface{}{
+			"baseBranch":     nil,
+			"since":          nil,
+			"reviewers":      nil,
+			"scorePrecision": nil,
+			"concurrency":    nil,
+			"toolAuthors":    []interface{}{},
+			"backend":        "shell",
+			"weight":         "blame",
+			"aggregation":    "sum",
+			"onlyPaths":      []interface{}{},
+			"hooks":          []interface{}{},
+		}},
+		{&ContributionCounter{
+			BaseBranch:           "develop",
+			Since:                "2017-01-01",
+			IgnoredExtensions:    []string{"md"},
+			OnlyExtensions:       []string{"go"},
+			MinDistinctReviewers: 5,
+			Backend:              GoGitBackend{},
+			Weight:               WeightCommits,
+			Aggregation:          AggregateMax,
+			ScorePrecision:       WholeNumbers,
+			PostProcess:          func(s Stats) Stats { return s },
+		}, map[string]interface{}{
+			"baseBranch":           "develop",
+			"since":                "2017-01-01",
+			"ignoredExtensions":    []interface{}{"md"},
+			"onlyExtensions":       []interface{}{"go"},
+			"reviewers":            nil,
+			"minDistinctReviewers": 5.0,
+			"scorePrecision":       -1.0,
+			"backend":              "go-git",
+			"weight":               "commits",
+			"aggregation":          "max",
+			"hooks":                []interface{}{"PostProcess"},
+		}},
+	}
+
+	for _, c := range cases {
+		data, err := c.Counter.EffectiveConfig()
+		if err != nil {
+			t.Fatalf("Unexpected error describing config: %v\n", err)
+		}
+
+		var doc map[string]interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("Unable to parse config: %v\n%s", err, data)
+		}
+
+		for key, expected := range c.Expected {
+			if !reflect.DeepEqual(doc[key], expected) {
+				t.Errorf("Got %s %v, expected %v\n", key, doc[key], expected)
+			}
+		}
+	}
+
+	// Defaults are described, not applied
+	r := &ContributionCounter{}
+	if _, err := r.EffectiveConfig(); err != nil || r.Since != "" {
+		t.Errorf("Expected config to leave the counter alone, got Since '%s' and error %v\n", r.Since, err)
+	}
+}
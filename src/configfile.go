@@ -0,0 +1,177 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ConfigFileName is the file at the root of a repository that teams check in
+// so everyone finds reviewers with the same options. See LoadConfig.
+const ConfigFileName = ".gitreviewers.json"
+
+// LoadConfig reads the options in the JSON file at path, usually
+// ConfigFileName at the root of the repository, into a new counter. The
+// file has the same keys EffectiveConfig writes, so its output can be
+// checked in as it is, and keys left out or zero keep their defaults.
+// Weight, Aggregation, and Backend are given by name, such as "commits",
+// TimeZone by IANA name such as "Europe/Berlin", and WorkingHours like
+// "9:00-18:00 Europe/Berlin". Reviewers sets MaxReviewers.
+//
+// Hooks and a "custom" backend are only named, so they are ignored, as is
+// workDir, since the file's own location says which repository it is for.
+// Anything passed on to git, commandPrefix and extraLogArgs, can't be set
+// from a file and must be empty. Unknown keys are an error. A missing file
+// gives a zero-value counter.
+func LoadConfig(path string) (*ContributionCounter, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &ContributionCounter{}, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to read config")
+	}
+
+	var cfg effectiveConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, errors.Wrapf(err, "invalid config in '%s'", path)
+	}
+
+	if len(cfg.CommandPrefix) > 0 || len(cfg.ExtraLogArgs) > 0 {
+		return nil, errors.Errorf("invalid config in '%s': commandPrefix and extraLogArgs can't be set from a file", path)
+	}
+
+	r := &ContributionCounter{
+		BaseBranch:            cfg.BaseBranch,
+		Since:                 cfg.Since,
+		Until:                 cfg.Until,
+		IgnoredExtensions:     cfg.IgnoredExtensions,
+		OnlyExtensions:        cfg.OnlyExtensions,
+		IgnoredPaths:          cfg.IgnoredPaths,
+		OnlyPaths:             cfg.OnlyPaths,
+		OnlyLanguages:         cfg.OnlyLanguages,
+		NoScorePaths:          cfg.NoScorePaths,
+		CriticalPaths:         cfg.CriticalPaths,
+		SkipToolChurn:         cfg.SkipToolChurn,
+		ToolAuthors:           cfg.ToolAuthors,
+		MaxReviewers:          cfg.Reviewers,
+		StrictMaxReviewers:    cfg.StrictMaxReviewers,
+		ExcludeSelf:           cfg.ExcludeSelf,
+		SelfIdentity:          cfg.SelfIdentity,
+		ExcludedReviewers:     cfg.ExcludedReviewers,
+		IncludeBots:           cfg.IncludeBots,
+		IgnoredAuthorPatterns: cfg.IgnoredAuthorPatterns,
+		MergeEmailPrefixes:    cfg.MergeEmailPrefixes,
+		IgnoreMailmap:         cfg.IgnoreMailmap,
+		ExcludeOffHours:       cfg.ExcludeOffHours,
+		MinCommits:            cfg.MinCommits,
+		MinDistinctReviewers:  cfg.MinDistinctReviewers,
+		PreferFastReviewers:   cfg.PreferFastReviewers,
+		PreferWorkingHours:    cfg.PreferWorkingHours,
+		SquashConsecutive:     cfg.SquashConsecutive,
+		NetChangesOnly:        cfg.NetChangesOnly,
+		IgnoreImportCommit:    cfg.IgnoreImportCommit,
+		DetectCopies:          cfg.DetectCopies,
+		ActivityWeight:        cfg.ActivityWeight,
+		DependencyOrder:       cfg.DependencyOrder,
+		MaxDiffFiles:          cfg.MaxDiffFiles,
+		ScorePrecision:        cfg.ScorePrecision,
+		MaxWidth:              cfg.MaxWidth,
+		Concurrency:           cfg.Concurrency,
+		RandSeed:              cfg.RandSeed,
+		RateLimit:             cfg.RateLimit,
+		DirDependencies:       cfg.DirDependencies,
+	}
+
+	if r.TimeZone, err = loadLocation(cfg.TimeZone); err != nil {
+		return nil, err
+	}
+	if r.WorkingHours, err = parseWorkingHours(cfg.WorkingHours); err != nil {
+		return nil, err
+	}
+
+	found := len(cfg.Weight) == 0
+	for weight, name := range weightNames {
+		if name == cfg.Weight {
+			r.Weight, found = weight, true
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("unknown weight '%s' in config", cfg.Weight)
+	}
+
+	found = len(cfg.Aggregation) == 0
+	for aggregation, name := range aggregationNames {
+		if name == cfg.Aggregation {
+			r.Aggregation, found = aggregation, true
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("unknown aggregation '%s' in config", cfg.Aggregation)
+	}
+
+	switch cfg.Backend {
+	case "", "custom":
+	case "shell":
+		r.Backend = ShellBackend{}
+	case "go-git":
+		r.Backend = GoGitBackend{}
+	default:
+		return nil, errors.Errorf("unknown backend '%s' in config", cfg.Backend)
+	}
+
+	return r, nil
+}
+
+// parseWorkingHours reads WorkingHours written like its String, as
+// "9:00-18:00" optionally followed by a time zone's IANA name. Empty is the
+// zero value.
+func parseWorkingHours(s string) (WorkingHours, error) {
+	var w WorkingHours
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return w, nil
+	}
+
+	var rest string
+	if n, _ := fmt.Sscanf(fields[0], "%d:00-%d:00%s", &w.Start, &w.End, &rest); n != 2 || len(fields) > 2 ||
+		w.Start < 0 || w.Start > 23 || w.End < 0 || w.End > 24 {
+		return w, errors.Errorf("invalid working hours '%s' in config", s)
+	}
+
+	if len(fields) == 2 {
+		var err error
+		if w.Location, err = loadLocation(fields[1]); err != nil {
+			return w, err
+		}
+	}
+
+	return w, nil
+}
+
+// loadLocation finds the time zone with the IANA name, or none for an empty
+// name.
+func loadLocation(name string) (*time.Location, error) {
+	if len(name) == 0 {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unknown time zone '%s' in config", name)
+	}
+
+	return loc, nil
+}
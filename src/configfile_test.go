@@ -0,0 +1,199 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-reviewer-config")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ConfigFileName)
+	write := func(config string) {
+		if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+			t.Fatalf("Unable to write config: %v\n", err)
+		}
+	}
+
+	// A missing file changes nothing
+	r, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading a missing config: %v\n", err)
+	}
+	if !reflect.DeepEqual(r, &ContributionCounter{}) {
+		t.Errorf("Got counter %+v for a missing config, expected the zero value\n", r)
+	}
+
+	write(`{
+  "since": "2017-01-01",
+  "ignoredExtensions": ["md"],
+  "onlyPaths": ["src", "internal/**"],
+  "reviewers": 5,
+  "excludeSelf": true,
+  "weight": "commits",
+  "aggregation": "max",
+  "backend": "go-git",
+  "timeZone": "UTC",
+  "workingHours": "8:00-16:00",
+  "scorePrecision": -1,
+  "hooks": ["Veto"],
+  "dirDependencies": {"cmd": ["lib"]}
+}`)
+	if r, err = LoadConfig(path); err != nil {
+		t.Fatalf("Unexpected error loading config: %v\n", err)
+	}
+
+	if r.Since != "2017-01-01" || r.MaxReviewers != 5 || !r.ExcludeSelf {
+		t.Errorf("Got Since '%s', MaxReviewers %d, and ExcludeSelf %t, expected the config's\n",
+			r.Since, r.MaxReviewers, r.ExcludeSelf)
+	}
+	if expected := []string{"md"}; !reflect.DeepEqual(r.IgnoredExtensions, expected) {
+		t.Errorf("Got IgnoredExtensions %v, expected %v\n", r.IgnoredExtensions, expected)
+	}
+	if expected := []string{"src", "internal/**"}; !reflect.DeepEqual(r.OnlyPaths, expected) {
+		t.Errorf("Got OnlyPaths %v, expected %v\n", r.OnlyPaths, expected)
+	}
+	if r.Weight != WeightCommits || r.Aggregation != AggregateMax {
+		t.Errorf("Got weight %s and aggregation %s, expected commits and max\n",
+			weightNames[r.Weight], aggregationNames[r.Aggregation])
+	}
+	if _, ok := r.Backend.(GoGitBackend); !ok {
+		t.Errorf("Got backend %T, expected GoGitBackend\n", r.Backend)
+	}
+	if r.TimeZone == nil || r.TimeZone.String() != "UTC" {
+		t.Errorf("Got time zone %v, expected UTC\n", r.TimeZone)
+	}
+	if r.ScorePrecision != WholeNumbers {
+		t.Errorf("Got ScorePrecision %d, expected WholeNumbers\n", r.ScorePrecision)
+	}
+	if actual := r.WorkingHours.String(); actual != "8:00-16:00" {
+		t.Errorf("Got working hours %s, expected 8:00-16:00\n", actual)
+	}
+	if expected := map[string][]string{"cmd": {"lib"}}; !reflect.DeepEqual(r.DirDependencies, expected) {
+		t.Errorf("Got DirDependencies %v, expected %v\n", r.DirDependencies, expected)
+	}
+
+	malformed := []struct {
+		Config   string
+		Expected string
+	}{
+		{`{"since": "2017-01-01",`, "invalid config"},
+		{`{"reviewers": "five"}`, "invalid config"},
+		{`{"maxReviewerz": 5}`, "unknown field"},
+		{`{"showFiles": true}`, "unknown field"},
+		{`{"commandPrefix": ["sh", "-c", "true"]}`, "can't be set from a file"},
+		{`{"extraLogArgs": ["--output=/tmp/overwritten"]}`, "can't be set from a file"},
+		{`{"weight": "heaviest"}`, "unknown weight"},
+		{`{"backend": "svn"}`, "unknown backend"},
+		{`{"timeZone": "Nowhere/Special"}`, "unknown time zone"},
+		{`{"workingHours": "9:30-18:00"}`, "invalid working hours"},
+		{`{"workingHours": "9:00-18:00 Nowhere/Special"}`, "unknown time zone"},
+	}
+	for _, c := range malformed {
+		write(c.Config)
+		if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), c.Expected) {
+			t.Errorf("Got error '%v' loading %s, expected one mentioning '%s'\n", err, c.Config, c.Expected)
+		}
+	}
+}
+
+func TestLoadConfigRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-reviewer-config")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Time zone Europe/Berlin isn't available: %v\n", err)
+	}
+
+	// What EffectiveConfig describes loads back to the same description
+	r := &ContributionCounter{
+		BaseBranch:     "develop",
+		Since:          "2017-01-01",
+		OnlyExtensions: []string{"go"},
+		MaxReviewers:   2,
+		Weight:         WeightLines,
+		Aggregation:    AggregateMean,
+		Backend:        GoGitBackend{},
+		ScorePrecision: WholeNumbers,
+		TimeZone:       berlin,
+		WorkingHours:   WorkingHours{Start: 22, End: 6, Location: berlin},
+		Veto:           func(Stat, []string) bool { return false },
+	}
+	data, err := r.EffectiveConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error describing config: %v\n", err)
+	}
+
+	path := filepath.Join(dir, ConfigFileName)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Unable to write config: %v\n", err)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading described config: %v\n%s", err, data)
+	}
+	reloaded, err := loaded.EffectiveConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error describing loaded config: %v\n", err)
+	}
+
+	// Only the hook can't come back
+	expected := strings.Replace(string(data), `"hooks": [
+    "Veto"
+  ]`, `"hooks": []`, 1)
+	if string(reloaded) != expected {
+		t.Errorf("Got config:\n%s\nexpected:\n%s", reloaded, expected)
+	}
+}
+
+func TestLoadConfigKeepsDefaults(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	// Defaults resolved for one run, on one machine, aren't pinned by saving
+	// the config
+	r := f.counter()
+	if _, err := r.FindReviewerStats([]string{"main.go"}); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	data, err := r.EffectiveConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error describing config: %v\n", err)
+	}
+
+	path := filepath.Join(f.dir, ConfigFileName)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Unable to write config: %v\n", err)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading described config: %v\n%s", err, data)
+	}
+	if loaded.Since != "" || loaded.BaseBranch != "" || loaded.Concurrency != 0 || loaded.ScorePrecision != 0 {
+		t.Errorf("Got Since '%s', BaseBranch '%s', Concurrency %d, and ScorePrecision %d, expected them unset:\n%s",
+			loaded.Since, loaded.BaseBranch, loaded.Concurrency, loaded.ScorePrecision, data)
+	}
+	if len(loaded.ToolAuthors) > 0 || len(loaded.IgnoredAuthorPatterns) > 0 || len(loaded.IgnoredExtensions) > 0 {
+		t.Errorf("Expected the default lists to be left out:\n%s", data)
+	}
+}
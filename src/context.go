@@ -0,0 +1,64 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"context"
+)
+
+// FindReviewersContext is FindReviewers, but stops as soon as ctx is done:
+// running git commands are killed, and ctx.Err() is returned.
+func (r *ContributionCounter) FindReviewersContext(ctx context.Context, paths []string) (string, error) {
+	var out string
+	err := r.withContext(ctx, func() (err error) {
+		out, err = r.FindReviewers(paths)
+		return err
+	})
+
+	return out, err
+}
+
+// FindReviewerStatsContext is FindReviewerStats, but stops as soon as ctx is
+// done: running git commands are killed, and ctx.Err() is returned.
+func (r *ContributionCounter) FindReviewerStatsContext(ctx context.Context, paths []string) (Stats, error) {
+	var stats Stats
+	err := r.withContext(ctx, func() (err error) {
+		stats, err = r.FindReviewerStats(paths)
+		return err
+	})
+
+	return stats, err
+}
+
+// withContext runs fn with ctx governing the counter's git commands and
+// Eligibility checks. A counter runs under one context at a time. Failures
+// caused by ctx ending, such as a killed git command, are reported as
+// ctx.Err().
+func (r *ContributionCounter) withContext(ctx context.Context, fn func() error) error {
+	if r == nil {
+		return ErrNilCounter
+	}
+
+	r.ctx = ctx
+	defer func() { r.ctx = nil }()
+
+	err := fn()
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// context is what the counter's work runs under, which is never done unless
+// a caller passed in a context of their own.
+func (r *ContributionCounter) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+
+	return r.ctx
+}
@@ -0,0 +1,54 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFindReviewersContext(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+
+	if _, err := r.FindReviewersContext(context.Background(), files); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	// Every blame hangs until it's killed
+	wrapGit(r, "-", `[ "$sub" = blame ] && exec sleep 30; exec "$@"`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = r.FindReviewerStatsContext(ctx, files)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Got error '%v', expected the context's\n", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Took %v to give up, expected to stop promptly\n", elapsed)
+	}
+
+	// The counter isn't left cancelled
+	r.CommandPrefix = nil
+	if _, err := r.FindReviewerStats(files); err != nil {
+		t.Errorf("Unexpected error finding reviewers after cancelling: %v\n", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.FindReviewersContext(cancelled, files); err != context.Canceled {
+		t.Errorf("Got error '%v' with a cancelled context, expected context.Canceled\n", err)
+	}
+}
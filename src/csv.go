@@ -0,0 +1,118 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// csvColumns maps the columns a CSVFormatter can emit to how each is read
+// from a Stat. Reviewers are identified by email, so the reviewer and email
+// columns carry the same value; name is the one they commit under. This
+// differs from Stats.WriteCSV, whose reviewer column holds the name.
+var csvColumns = map[string]func(*Stat) string{
+	"reviewer":    func(s *Stat) string { return s.Reviewer },
+	"name":        func(s *Stat) string { return s.Name },
+	"email":       func(s *Stat) string { return s.Reviewer },
+	"count":       func(s *Stat) string { return strconv.FormatInt(s.Count, 10) },
+	"files":       func(s *Stat) string { return strconv.Itoa(s.Files) },
+	"share":       func(s *Stat) string { return strconv.FormatFloat(s.Percentage, 'f', 4, 64) },
+	"last_commit": func(s *Stat) string { return s.LastCommit },
+}
+
+// defaultCSVColumns are emitted when a CSVFormatter doesn't name any.
+var defaultCSVColumns = []string{"reviewer", "count", "share"}
+
+// CSVFormatter writes Stats as CSV for spreadsheet workflows.
+type CSVFormatter struct {
+	// Columns lists which of reviewer, name, email, count, files, share,
+	// and last_commit to emit, in order. Defaults to reviewer, count, share.
+	Columns []string
+
+	// Header adds a first row naming the columns.
+	Header bool
+}
+
+// Write emits one row per Stat to w. Values are quoted as needed, so names
+// containing commas or quotes are safe.
+func (f CSVFormatter) Write(w io.Writer, s Stats) error {
+	columns := f.Columns
+	if len(columns) == 0 {
+		columns = defaultCSVColumns
+	}
+
+	getters := make([]func(*Stat) string, len(columns))
+	for i, c := range columns {
+		get, ok := csvColumns[c]
+		if !ok {
+			return errors.Errorf("unknown CSV column '%s'", c)
+		}
+		getters[i] = get
+	}
+
+	cw := csv.NewWriter(w)
+	if f.Header {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+
+	row := make([]string, len(columns))
+	for _, stat := range s {
+		for i, get := range getters {
+			row[i] = get(stat)
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes the Stats to w as CSV with a reviewer,email,count header.
+// The reviewer column holds the name each reviewer commits under, or their
+// email when that isn't known. That makes it CSVFormatter's name column with
+// a fallback, not its reviewer column, which is always the email.
+func (s Stats) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"reviewer", "email", "count"}); err != nil {
+		return err
+	}
+
+	for _, stat := range s {
+		name := stat.Name
+		if len(name) == 0 {
+			name = stat.Reviewer
+		}
+
+		if err := cw.Write([]string{name, stat.Reviewer, strconv.FormatInt(stat.Count, 10)}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// FindReviewersCSV finds the top reviewers for the changed paths like
+// FindReviewerStats and writes them to w with WriteCSV.
+func (r *ContributionCounter) FindReviewersCSV(paths []string, w io.Writer) error {
+	defer r.startRun()()
+
+	stats, err := r.FindReviewerStats(paths)
+	if err != nil {
+		return err
+	}
+
+	return stats.WriteCSV(w)
+}
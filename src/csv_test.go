@@ -0,0 +1,111 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+var csvStats = Stats{
+	&Stat{Reviewer: "Doe, Jane \"JD\"", Percentage: 0.75, Count: 3, LastCommit: "2017-06-01"},
+	&Stat{Reviewer: "abe@git-reviewer.com", Percentage: 0.25, Count: 1, LastCommit: "2017-05-01"},
+}
+
+func TestCSVFormatterDefaults(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (CSVFormatter{}).Write(&buf, csvStats); err != nil {
+		t.Fatalf("Unexpected error writing CSV: %v\n", err)
+	}
+
+	expected := "\"Doe, Jane \"\"JD\"\"\",3,0.7500\n" +
+		"abe@git-reviewer.com,1,0.2500\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Got CSV:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestCSVFormatterColumns(t *testing.T) {
+	var buf bytes.Buffer
+	f := CSVFormatter{Columns: []string{"last_commit", "email"}, Header: true}
+	if err := f.Write(&buf, csvStats); err != nil {
+		t.Fatalf("Unexpected error writing CSV: %v\n", err)
+	}
+
+	expected := "last_commit,email\n" +
+		"2017-06-01,\"Doe, Jane \"\"JD\"\"\"\n" +
+		"2017-05-01,abe@git-reviewer.com\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Got CSV:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestCSVFormatterUnknownColumn(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (CSVFormatter{Columns: []string{"shoe_size"}}).Write(&buf, csvStats); err == nil {
+		t.Error("Expected an error for an unknown column")
+	}
+}
+
+func TestCSVFormatterLargeCount(t *testing.T) {
+	var buf bytes.Buffer
+	f := CSVFormatter{Columns: []string{"count"}}
+	if err := f.Write(&buf, Stats{&Stat{Reviewer: "abe", Count: 5000000000}}); err != nil {
+		t.Fatalf("Unexpected error writing CSV: %v\n", err)
+	}
+
+	if actual := buf.String(); actual != "5000000000\n" {
+		t.Errorf("Got CSV '%s', expected the full count\n", actual)
+	}
+}
+
+func TestStatsWriteCSV(t *testing.T) {
+	stats := Stats{
+		&Stat{Reviewer: "jane@git-reviewer.com", Name: `Doe, Jane "JD"`, Count: 3},
+		&Stat{Reviewer: "abe@git-reviewer.com", Count: 1},
+		&Stat{Name: "No Email", Count: 2},
+	}
+
+	var buf bytes.Buffer
+	if err := stats.WriteCSV(&buf); err != nil {
+		t.Fatalf("Unexpected error writing CSV: %v\n", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Unable to parse CSV: %v\n", err)
+	}
+
+	expected := [][]string{
+		{"reviewer", "email", "count"},
+		{`Doe, Jane "JD"`, "jane@git-reviewer.com", "3"},
+		{"abe@git-reviewer.com", "abe@git-reviewer.com", "1"},
+		{"No Email", "", "2"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Got rows %q, expected %q\n", rows, expected)
+	}
+}
+
+func TestFindReviewersCSV(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	var buf bytes.Buffer
+	if err := f.counter().FindReviewersCSV([]string{"main.go"}, &buf); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Unable to parse CSV: %v\n", err)
+	}
+	if len(rows) != 2 || rows[1][1] != "abe@git-reviewer.com" || rows[1][2] != "3" {
+		t.Errorf("Got rows %q, expected a header and abe with 3 lines\n", rows)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import "time"
+
+// location is where days are reckoned when one has to be picked: TimeZone,
+// or else the host's local time.
+func (r *ContributionCounter) location() *time.Location {
+	if r.TimeZone != nil {
+		return r.TimeZone
+	}
+
+	return time.Local
+}
+
+// timeZoneName names TimeZone for EffectiveConfig, or is empty without one.
+func (r *ContributionCounter) timeZoneName() string {
+	if r.TimeZone == nil {
+		return ""
+	}
+
+	return r.TimeZone.String()
+}
+
+// gitDate turns a "YYYY-MM-DD" day into a date for git's --since. Without
+// TimeZone that is the day itself, which git starts at midnight on the host;
+// with it, the moment the day starts there, offset included. Anything that
+// isn't a day is passed along for git to make sense of.
+func (r *ContributionCounter) gitDate(day string) string {
+	if r.TimeZone == nil {
+		return day
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", day, r.TimeZone)
+	if err != nil {
+		return day
+	}
+
+	return start.Format(time.RFC3339)
+}
+
+// day is the "YYYY-MM-DD" day t fell on in TimeZone, or without one, in t's
+// own zone, which for commits is their author's.
+func (r *ContributionCounter) day(t time.Time) string {
+	if r.TimeZone != nil {
+		t = t.In(r.TimeZone)
+	}
+
+	return t.Format("2006-01-02")
+}
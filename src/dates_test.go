@@ -0,0 +1,97 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTimeZoneWindow(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// Committed in UTC, around midnight on either side of the 10th in Tokyo
+	at := func(s string) time.Time {
+		when, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("Unable to parse %s: %v\n", s, err)
+		}
+		return when
+	}
+	f.commitAt("abe@git-reviewer.com", at("2020-03-09T14:30:00Z"), map[string]string{"a.go": "1\n"})
+	f.commitAt("george@git-reviewer.com", at("2020-03-09T15:30:00Z"), map[string]string{"a.go": "1\n2\n"})
+	f.commitAt("tom@git-reviewer.com", at("2020-03-10T14:30:00Z"), map[string]string{"a.go": "1\n2\n3\n"})
+	f.commitAt("bob@git-reviewer.com", at("2020-03-10T15:30:00Z"), map[string]string{"a.go": "1\n2\n3\n4\n"})
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	cases := []struct {
+		Zone     *time.Location
+		Expected []string
+	}{
+		{tokyo, []string{"george@git-reviewer.com", "tom@git-reviewer.com"}},
+		{time.UTC, []string{"bob@git-reviewer.com", "tom@git-reviewer.com"}},
+	}
+
+	defer os.Setenv("TZ", os.Getenv("TZ"))
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+
+	for _, host := range []string{"UTC", "Asia/Tokyo", "America/Los_Angeles"} {
+		local, err := time.LoadLocation(host)
+		if err != nil {
+			t.Skipf("Time zone %s isn't available: %v\n", host, err)
+		}
+		os.Setenv("TZ", host)
+		time.Local = local
+
+		for _, c := range cases {
+			for _, backend := range []Backend{ShellBackend{}, GoGitBackend{}} {
+				find := func(weight Weight) []string {
+					r := f.counter()
+					r.Backend = backend
+					r.Weight = weight
+					r.Since, r.Until = "2020-03-10", "2020-03-10"
+					r.TimeZone = c.Zone
+					r.MaxReviewers = 10
+
+					stats, err := r.FindReviewerStats([]string{"a.go"})
+					if err != nil {
+						t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+					}
+
+					var reviewers []string
+					for _, stat := range stats {
+						reviewers = append(reviewers, stat.Reviewer)
+					}
+					sort.Strings(reviewers)
+					return reviewers
+				}
+
+				for _, weight := range []Weight{WeightBlame, WeightCommits} {
+					if actual := find(weight); !reflect.DeepEqual(actual, c.Expected) {
+						t.Errorf("Got reviewers %v in %s on a %s host with %T and weight %s, expected %v\n",
+							actual, c.Zone, host, backend, weightNames[weight], c.Expected)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGitDate(t *testing.T) {
+	r := &ContributionCounter{}
+	if actual := r.gitDate("2020-03-10"); actual != "2020-03-10" {
+		t.Errorf("Got %s without a time zone, expected the day itself\n", actual)
+	}
+
+	r.TimeZone = time.FixedZone("", -5*60*60)
+	if actual := r.gitDate("2020-03-10"); actual != "2020-03-10T00:00:00-05:00" {
+		t.Errorf("Got %s with a time zone, expected the start of the day there\n", actual)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// ReviewSequence finds the top reviewers for each directory like
+// ReviewersByDir and lists the groups in the order to review them, for
+// staged reviews. With DependencyOrder, a directory comes after every one it
+// depends on, directly or through others, and directories are otherwise
+// ordered by key as in SortedGroups. Directories that depend on each other
+// are ordered by key among themselves.
+func (r *ContributionCounter) ReviewSequence(paths []string) ([]Group, error) {
+	defer r.startRun()()
+
+	groups, err := r.ReviewersByDir(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := SortedGroups(groups)
+	if !r.DependencyOrder || len(sorted) < 2 {
+		return sorted, nil
+	}
+
+	deps := r.DirDependencies
+	if deps == nil {
+		dirs := make([]string, len(sorted))
+		for i, g := range sorted {
+			dirs[i] = g.Key
+		}
+		if deps, err = r.goDependencies(dirs); err != nil {
+			return nil, err
+		}
+	}
+
+	return dependencyOrder(sorted, deps), nil
+}
+
+// dependencyOrder puts groups, sorted by key, after the groups they depend
+// on. Whenever several are free to go next the first by key does, and when
+// none is, as in a cycle, the first remaining by key goes regardless.
+func dependencyOrder(sorted []Group, deps map[string][]string) []Group {
+	waiting := make(map[string]map[string]bool, len(sorted))
+	for _, g := range sorted {
+		waiting[g.Key] = make(map[string]bool)
+	}
+	for _, g := range sorted {
+		for dep := range reachable(g.Key, deps) {
+			if _, ok := waiting[dep]; ok && dep != g.Key {
+				waiting[g.Key][dep] = true
+			}
+		}
+	}
+
+	ordered := make([]Group, 0, len(sorted))
+	done := make(map[string]bool, len(sorted))
+	for len(ordered) < len(sorted) {
+		next := -1
+		for i, g := range sorted {
+			if done[g.Key] {
+				continue
+			}
+			if next < 0 {
+				next = i
+			}
+
+			ready := true
+			for dep := range waiting[g.Key] {
+				ready = ready && done[dep]
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+
+		done[sorted[next].Key] = true
+		ordered = append(ordered, sorted[next])
+	}
+
+	return ordered
+}
+
+// reachable finds every directory dir depends on through deps.
+func reachable(dir string, deps map[string][]string) map[string]bool {
+	seen := make(map[string]bool)
+	stack := append([]string{}, deps[dir]...)
+	for len(stack) > 0 {
+		d := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		stack = append(stack, deps[d]...)
+	}
+
+	return seen
+}
+
+// goDependencies guesses how the dirs depend on each other from the imports
+// of their Go files at HEAD. An import depends on a directory when its path
+// ends with the directory's, as in "example.com/project/lib/util" for
+// lib/util. The root directory can't be told apart this way and never counts
+// as a dependency.
+func (r *ContributionCounter) goDependencies(dirs []string) (map[string][]string, error) {
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	h, err := r.backend().Resolve(r, "HEAD")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to resolve HEAD")
+	}
+	head, err := r.Repo.CommitObject(h)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read HEAD")
+	}
+	root, err := head.Tree()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read HEAD tree")
+	}
+
+	deps := make(map[string][]string)
+	for _, dir := range dirs {
+		imports, err := goImports(root, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dep := range dirs {
+			if dep == dir || dep == "." {
+				continue
+			}
+			for _, imp := range imports {
+				if imp == dep || strings.HasSuffix(imp, "/"+dep) {
+					deps[dir] = append(deps[dir], dep)
+					break
+				}
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// goImports lists the import paths of the Go files directly in dir, leaving
+// out tests. Files that don't parse are skipped.
+func goImports(root *object.Tree, dir string) ([]string, error) {
+	tree := root
+	if dir != "." {
+		var err error
+		if tree, err = root.Tree(dir); err == object.ErrDirectoryNotFound {
+			return nil, nil
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "unable to read directory '%s'", dir)
+		}
+	}
+
+	var imports []string
+	fset := token.NewFileSet()
+	for _, entry := range tree.Entries {
+		name := entry.Name
+		if !entry.Mode.IsFile() || path.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		f, err := tree.TreeEntryFile(&entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read '%s'", path.Join(dir, name))
+		}
+		src, err := f.Contents()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read '%s'", path.Join(dir, name))
+		}
+
+		parsed, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, spec := range parsed.Imports {
+			if imp, err := strconv.Unquote(spec.Path.Value); err == nil {
+				imports = append(imports, imp)
+			}
+		}
+	}
+
+	return imports, nil
+}
@@ -0,0 +1,72 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReviewSequence(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// cmd/app builds on lib/core, which builds on lib/util
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"lib/util/util.go": "package util\n",
+		"lib/core/core.go": "package core\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/m/lib/util\"\n)\n",
+		"cmd/app/main.go":  "package main\n\nimport \"example.com/m/lib/core\"\n",
+	})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"lib/util/util.go": "package util\n\n// Util\n",
+		"lib/core/core.go": "package core\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/m/lib/util\"\n)\n\n// Core\n",
+		"cmd/app/main.go":  "package main\n\nimport \"example.com/m/lib/core\"\n\n// Main\n",
+	})
+	paths := []string{"cmd/app/main.go", "lib/core/core.go", "lib/util/util.go"}
+
+	cases := []struct {
+		Name     string
+		Ordered  bool
+		Deps     map[string][]string
+		Expected []string
+	}{
+		{"by key", false, nil, []string{"cmd/app", "lib/core", "lib/util"}},
+		{"by imports", true, nil, []string{"lib/util", "lib/core", "cmd/app"}},
+		// cmd/app depends on lib/util through a directory that didn't change
+		{"by given graph", true, map[string][]string{
+			"cmd/app":   {"tools/gen"},
+			"tools/gen": {"lib/util"},
+		}, []string{"lib/core", "lib/util", "cmd/app"}},
+		{"with a cycle", true, map[string][]string{
+			"lib/core": {"lib/util"},
+			"lib/util": {"lib/core"},
+		}, []string{"cmd/app", "lib/core", "lib/util"}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.DependencyOrder = c.Ordered
+		r.DirDependencies = c.Deps
+
+		groups, err := r.ReviewSequence(paths)
+		if err != nil {
+			t.Fatalf("Unexpected error ordering %s: %v\n", c.Name, err)
+		}
+
+		var actual []string
+		for _, g := range groups {
+			actual = append(actual, g.Key)
+			if len(g.Stats) == 0 || g.Stats[0].Reviewer != "abe@git-reviewer.com" {
+				t.Errorf("Got reviewers %v for %s, expected abe\n", g.Stats, g.Key)
+			}
+		}
+
+		if !reflect.DeepEqual(actual, c.Expected) {
+			t.Errorf("Got order %v %s, expected %v\n", actual, c.Name, c.Expected)
+		}
+	}
+}
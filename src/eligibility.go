@@ -0,0 +1,91 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// EligibilityChecker decides whether a collaborator may be suggested as a
+// reviewer. Organizations with a central reviewer-eligibility service can
+// implement it to filter out people who have left, are on leave, or lack the
+// required permissions.
+type EligibilityChecker interface {
+	Eligible(ctx context.Context, email string) (bool, error)
+}
+
+// AllEligible is the default EligibilityChecker. It allows everyone.
+type AllEligible struct{}
+
+// Eligible always reports true.
+func (AllEligible) Eligible(ctx context.Context, email string) (bool, error) {
+	return true, nil
+}
+
+// filterEligible drops the Stats whose reviewer the Eligibility checker
+// rejects. Answers are remembered for the rest of the run (see startRun), so
+// each person is only checked once however many times reviewers are scored
+// in it.
+func (r *ContributionCounter) filterEligible(s Stats) (Stats, error) {
+	if r.Eligibility == nil {
+		return s, nil
+	}
+
+	var kept Stats
+	for _, stat := range s {
+		ok, cached := r.eligibleAnswer(stat.Reviewer)
+		r.count(func(m *Metrics) {
+			if cached {
+				m.CacheHits++
+			} else {
+				m.CacheMisses++
+			}
+		})
+		if !cached {
+			var err error
+			ok, err = r.Eligibility.Eligible(r.context(), stat.Reviewer)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to check eligibility of %s", stat.Reviewer)
+			}
+			r.rememberEligible(stat.Reviewer, ok)
+		}
+
+		if ok {
+			kept = append(kept, stat)
+		}
+	}
+
+	return kept, nil
+}
+
+// eligibleAnswer looks up what Eligibility said about email earlier in the
+// current run.
+func (r *ContributionCounter) eligibleAnswer(email string) (ok, cached bool) {
+	if r.metrics == nil {
+		return false, false
+	}
+
+	r.metrics.mu.Lock()
+	defer r.metrics.mu.Unlock()
+
+	ok, cached = r.metrics.eligible[email]
+	return ok, cached
+}
+
+// rememberEligible keeps what Eligibility said about email for the rest of
+// the current run.
+func (r *ContributionCounter) rememberEligible(email string, ok bool) {
+	if r.metrics == nil {
+		return
+	}
+
+	r.metrics.mu.Lock()
+	defer r.metrics.mu.Unlock()
+
+	r.metrics.eligible[email] = ok
+}
@@ -0,0 +1,166 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"context"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// denyChecker rejects a single reviewer and counts how often it is asked.
+type denyChecker struct {
+	deny  string
+	calls map[string]int
+}
+
+func (d *denyChecker) Eligible(ctx context.Context, email string) (bool, error) {
+	d.calls[email]++
+	return email != d.deny, nil
+}
+
+func TestEligibilityChecker(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	checker := &denyChecker{deny: "abe@git-reviewer.com", calls: make(map[string]int)}
+	r := f.counter()
+	r.Eligibility = checker
+
+	for i := 0; i < 2; i++ {
+		out := f.reviewers(r)
+		if strings.Contains(out, "abe@git-reviewer.com") {
+			t.Errorf("Expected ineligible reviewer to be filtered, got:\n%s", out)
+		}
+		if !strings.Contains(out, "george@git-reviewer.com") {
+			t.Errorf("Expected eligible reviewer to remain, got:\n%s", out)
+		}
+	}
+
+	// Answers are remembered within a run but not across them
+	for email, calls := range checker.calls {
+		if calls != 2 {
+			t.Errorf("Checked %s %d times over 2 runs, expected once per run\n",
+				email, calls)
+		}
+	}
+}
+
+func TestEligibilityPerRun(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	checker := &denyChecker{calls: make(map[string]int)}
+	r := f.counter()
+	r.Eligibility = checker
+	paths := []string{"main.go", "util.go", "doc.go"}
+	expectCalls := func(when string, expected int) {
+		for _, email := range []string{"abe@git-reviewer.com", "george@git-reviewer.com"} {
+			if calls := checker.calls[email]; calls != expected {
+				t.Errorf("Checked %s %d times %s, expected %d\n", email, calls, when, expected)
+			}
+		}
+	}
+
+	// Every group is scored in the same run
+	if _, err := r.FindReviewersBatch([][]string{paths, paths}); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	expectCalls("after a batch of two groups", 1)
+
+	// Other entry points start runs of their own rather than reuse answers
+	if _, err := r.FindReport(paths); err != nil {
+		t.Fatalf("Unexpected error finding report: %v\n", err)
+	}
+	expectCalls("after a report", 2)
+
+	if _, err := r.WhyNot("george@git-reviewer.com", paths); err != nil {
+		t.Fatalf("Unexpected error asking why not: %v\n", err)
+	}
+	expectCalls("after asking why not", 3)
+
+	if _, err := r.AuthorsPerFile(paths); err != nil {
+		t.Fatalf("Unexpected error listing authors: %v\n", err)
+	}
+	if err := r.GenerateCodeowners(paths, ioutil.Discard, nil, 1); err != nil {
+		t.Fatalf("Unexpected error generating CODEOWNERS: %v\n", err)
+	}
+	expectCalls("after generating CODEOWNERS", 4)
+}
+
+func TestAllEligible(t *testing.T) {
+	if ok, err := (AllEligible{}).Eligible(context.Background(), "abe@git-reviewer.com"); !ok || err != nil {
+		t.Errorf("Expected AllEligible to allow everyone, got %v, %v\n", ok, err)
+	}
+}
+
+func TestCriticalPaths(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"security/auth.go": "1\n2\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"main.go": "1\n2\n3\n"})
+	paths := []string{"main.go", "security/auth.go"}
+
+	cases := []struct {
+		Deny     string
+		Expected error
+	}{
+		{"george@git-reviewer.com", nil},
+		// Only abe knows security/, and they can't review
+		{"abe@git-reviewer.com", ErrCriticalPathUncovered{Path: "security/auth.go"}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.CriticalPaths = []string{"security/"}
+		r.Eligibility = &denyChecker{deny: c.Deny, calls: make(map[string]int)}
+
+		if _, err := r.FindReviewerStats(paths); err != c.Expected {
+			t.Errorf("Got error '%v' denying %s, expected '%v'\n", err, c.Deny, c.Expected)
+		}
+	}
+}
+
+func TestVeto(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n7\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n7\n8\n9\n"})
+	f.commit("jane@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"})
+
+	var asked []string
+	r := f.counter()
+	r.Veto = func(reviewer Stat, paths []string) bool {
+		if len(paths) != 1 || paths[0] != "a.go" {
+			t.Errorf("Veto got paths %v, expected the reviewed paths\n", paths)
+		}
+		asked = append(asked, reviewer.Reviewer)
+		return reviewer.Reviewer == "abe@git-reviewer.com"
+	}
+
+	stats, err := r.FindReviewerStats([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if len(asked) != 4 {
+		t.Errorf("Veto was asked about %v, expected every candidate\n", asked)
+	}
+
+	// abe would be first, so jane moves up into the top three
+	var order []string
+	for _, stat := range stats {
+		order = append(order, stat.Reviewer)
+	}
+	expected := []string{"bob@git-reviewer.com", "george@git-reviewer.com", "jane@git-reviewer.com"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Got reviewers %v, expected %v\n", order, expected)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import "strings"
+
+// EvaluateSuggestions measures how well the reviewers suggested for paths
+// match the people who actually approved the change, such as a past pull
+// request. Precision is the fraction of the suggestions who approved, and
+// recall the fraction of the approvers who were suggested; either is 0 when
+// there is nobody to divide by. Approvers match reviewers by email, after the
+// mailmap, or by the handle Handles resolves for them when it is set, with or
+// without a leading "@".
+func (r *ContributionCounter) EvaluateSuggestions(paths []string, actualApprovers []string) (precision, recall float64, err error) {
+	defer r.startRun()()
+
+	suggested, err := r.FindReviewerStats(paths)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	approved := make(map[string]bool)
+	for _, approver := range actualApprovers {
+		approved[identityKey(reviewerKey(approver, r.Mailmap))] = true
+	}
+
+	agreed := 0
+	recalled := make(map[string]bool)
+	for _, stat := range suggested {
+		for _, id := range r.identities(stat.Reviewer) {
+			if approved[id] {
+				agreed++
+				recalled[id] = true
+				break
+			}
+		}
+	}
+
+	if len(suggested) > 0 {
+		precision = float64(agreed) / float64(len(suggested))
+	}
+	if len(approved) > 0 {
+		recall = float64(len(recalled)) / float64(len(approved))
+	}
+
+	return precision, recall, nil
+}
+
+// identities lists the keys a reviewer may be known by: their email, and
+// their handle if Handles knows it.
+func (r *ContributionCounter) identities(reviewer string) []string {
+	ids := []string{identityKey(reviewer)}
+	if r.Handles != nil {
+		if handle, ok := r.Handles(reviewer); ok {
+			ids = append(ids, identityKey(handle))
+		}
+	}
+
+	return ids
+}
+
+// identityKey normalizes an email or handle for comparison.
+func identityKey(id string) string {
+	return strings.ToLower(strings.TrimPrefix(id, "@"))
+}
@@ -0,0 +1,44 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import "testing"
+
+func TestEvaluateSuggestions(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	resolve := func(email string) (string, bool) {
+		return "george-gh", email == "george@git-reviewer.com"
+	}
+
+	// abe and george are suggested for these
+	cases := []struct {
+		Approvers         []string
+		Precision, Recall float64
+	}{
+		{[]string{"abe@git-reviewer.com", "carol@git-reviewer.com"}, 0.5, 0.5},
+		{[]string{"ABE@git-reviewer.com", "@george-gh"}, 1, 1},
+		{[]string{"abe@git-reviewer.com", "george@git-reviewer.com", "carol@git-reviewer.com", "dave@git-reviewer.com"}, 1, 0.5},
+		{[]string{"carol@git-reviewer.com"}, 0, 0},
+		{nil, 0, 0},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Handles = resolve
+
+		precision, recall, err := r.EvaluateSuggestions([]string{"main.go", "util.go"}, c.Approvers)
+		if err != nil {
+			t.Fatalf("Unexpected error evaluating suggestions: %v\n", err)
+		}
+
+		if precision != c.Precision || recall != c.Recall {
+			t.Errorf("Got precision %f and recall %f against %v, expected %f and %f\n",
+				precision, recall, c.Approvers, c.Precision, c.Recall)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Sources of the suggestions from FindReviewersExplained, strongest first.
+const (
+	// SourceCodeOwner: CODEOWNERS lists them for one of the changed files.
+	SourceCodeOwner = "CODEOWNER"
+	// SourceAuthor: they created one of the changed files.
+	SourceAuthor = "AUTHOR"
+	// SourceHistory: they have experience with the changed files, as found
+	// by FindReviewerStats.
+	SourceHistory = "HISTORY"
+)
+
+// sourceStrength orders the sources, strongest highest.
+var sourceStrength = map[string]int{
+	SourceCodeOwner: 3,
+	SourceAuthor:    2,
+	SourceHistory:   1,
+}
+
+// FindReviewersExplained merges the suggestions from FindReviewerStats with
+// the CODEOWNERS of the changed files and the people who created them,
+// setting the Source of each. Someone found several ways is suggested once,
+// under the strongest Source, and the suggestions are ordered by Source and
+// then as FindReviewerStats orders them. Owners and authors without
+// experience in the changed files have no Percentage or Rank, and owners
+// are suggested as CODEOWNERS names them unless they match a reviewer found
+// otherwise, by email or by handle as for OwnershipAgreement. Exclusions
+// apply to every source.
+func (r *ContributionCounter) FindReviewersExplained(paths []string) (Stats, error) {
+	defer r.startRun()()
+
+	history, err := r.FindReviewerStats(paths)
+	if _, ok := err.(ErrInsufficientReviewers); err != nil && !ok {
+		return nil, err
+	}
+	insufficient := err
+
+	paths = r.scoredPaths(paths)
+	owners, err := r.FindOwners(paths)
+	if err != nil {
+		return nil, err
+	}
+	creators, err := r.fileCreators(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(Stats, 0, len(history))
+	found := make(map[string]*Stat)
+	remember := func(stat *Stat) {
+		for _, id := range r.identities(stat.Reviewer) {
+			if _, ok := found[id]; !ok {
+				found[id] = stat
+			}
+		}
+	}
+	for _, stat := range history {
+		stat.Source = SourceHistory
+		merged = append(merged, stat)
+		remember(stat)
+	}
+
+	// Owners and authors found no other way are new suggestions, counted in
+	// the files they own or created
+	var extra Stats
+	add := func(byFile map[string][]string, source string) {
+		files := make(map[string]int)
+		spelled := make(map[string]string)
+		for _, p := range paths {
+			seen := make(map[string]bool)
+			for _, who := range byFile[p] {
+				id := identityKey(who)
+				if !seen[id] {
+					seen[id] = true
+					files[id]++
+				}
+				if _, ok := spelled[id]; !ok {
+					spelled[id] = who
+				}
+			}
+		}
+
+		var ids []string
+		for id := range files {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			if stat, ok := found[id]; ok {
+				if sourceStrength[source] > sourceStrength[stat.Source] {
+					stat.Source = source
+				}
+				continue
+			}
+
+			stat := &Stat{Reviewer: spelled[id], Files: files[id], Source: source}
+			extra = append(extra, stat)
+			remember(stat)
+		}
+	}
+	add(owners, SourceCodeOwner)
+	add(creators, SourceAuthor)
+
+	if extra, err = r.exclude(extra); err != nil {
+		return nil, err
+	}
+	merged = append(merged, extra...)
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return sourceStrength[merged[i].Source] > sourceStrength[merged[j].Source]
+	})
+
+	return merged, insufficient
+}
+
+// fileCreators finds who created each of the paths, in the history of the
+// base. A path created more than once, after being deleted, goes to whoever
+// created it first.
+func (r *ContributionCounter) fileCreators(paths []string) (map[string][]string, error) {
+	creators := make(map[string][]string)
+	if len(paths) == 0 {
+		return creators, nil
+	}
+
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.runsGit() {
+		err := r.walkHistory(base.Hash, "", func(c *object.Commit) error {
+			if c.NumParents() > 1 {
+				return nil
+			}
+
+			// The walk is newest first, so the earliest creation wins
+			added, err := commitPaths(c, true)
+			if err != nil {
+				return err
+			}
+			for _, p := range added {
+				if underAny(p, paths) {
+					creators[p] = []string{reviewerKey(c.Author.Email, r.Mailmap)}
+				}
+			}
+			return nil
+		})
+		return creators, err
+	}
+
+	args := append([]string{
+		"log", "--no-renames", "--diff-filter=A", "--name-only", "--format=%x00%ae",
+		base.Hash.String(), "--",
+	}, paths...)
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git log command")
+	}
+
+	// Every commit starts with a NUL-prefixed author line, followed by the
+	// names of the files it added. The log is newest first, so the earliest
+	// creation is seen last.
+	var author string
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			author = reviewerKey(line[1:], r.Mailmap)
+		case len(line) > 0:
+			creators[line] = []string{author}
+		}
+	}
+
+	return creators, nil
+}
@@ -0,0 +1,72 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindReviewersExplained(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// abe created a.go and owns everything, dave owns it by handle, carol
+	// created b.go but none of her lines are left, and george only has lines
+	f.commit("abe@git-reviewer.com", map[string]string{"api/a.go": "1\n2\n3\n4\n"})
+	f.commit("carol@git-reviewer.com", map[string]string{"api/b.go": "x\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"api/a.go": "1\n2\ng\n4\n", "api/b.go": "y\nz\n"})
+	f.commit("dave@git-reviewer.com", map[string]string{"api/a.go": "1\n2\ng\nd\n"})
+	f.commit("me@git-reviewer.com", map[string]string{
+		"CODEOWNERS": "/api/ abe@git-reviewer.com @org/web-team\n/api/a.go abe@git-reviewer.com @dave-gh\n",
+	})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"api/a.go": "0\n", "api/b.go": "0\n"})
+
+	r := f.counter()
+	r.MaxReviewers = 10
+	r.Handles = func(email string) (string, bool) {
+		return "dave-gh", email == "dave@git-reviewer.com"
+	}
+
+	stats, err := r.FindReviewersExplained([]string{"api/a.go", "api/b.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	type suggestion struct {
+		Reviewer, Source string
+		Files            int
+	}
+	var actual []suggestion
+	for _, stat := range stats {
+		actual = append(actual, suggestion{stat.Reviewer, stat.Source, stat.Files})
+	}
+
+	expected := []suggestion{
+		{"abe@git-reviewer.com", SourceCodeOwner, 1},
+		{"dave@git-reviewer.com", SourceCodeOwner, 1},
+		{"@org/web-team", SourceCodeOwner, 1},
+		{"carol@git-reviewer.com", SourceAuthor, 1},
+		{"george@git-reviewer.com", SourceHistory, 2},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Got suggestions %v, expected %v\n", actual, expected)
+	}
+
+	// Exclusions apply to owners and authors too
+	r = f.counter()
+	r.MaxReviewers = 10
+	r.ExcludedReviewers = []string{"carol", "web-team"}
+	if stats, err = r.FindReviewersExplained([]string{"api/a.go", "api/b.go"}); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	for _, stat := range stats {
+		if stat.Reviewer == "carol@git-reviewer.com" || stat.Reviewer == "@org/web-team" {
+			t.Errorf("Expected %s to be excluded\n", stat.Reviewer)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "gopkg.in/src-d/go-git.v4"
+)
+
+// fixture is a throwaway git repository tests can build history in. Commits
+// are stamped from a clock that advances a minute per commit so that history
+// is ordered deterministically and falls well within the default window.
+type fixture struct {
+	t     *testing.T
+	dir   string
+	clock time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	dir, err := ioutil.TempDir("", "git-reviewer")
+	if err != nil {
+		t.Fatalf("Unable to create fixture directory: %v\n", err)
+	}
+
+	f := &fixture{t: t, dir: dir, clock: time.Now().Add(-24 * time.Hour)}
+	f.git("init", "-q")
+	f.git("symbolic-ref", "HEAD", "refs/heads/master")
+	f.git("config", "user.name", "Me")
+	f.git("config", "user.email", "me@git-reviewer.com")
+	f.git("config", "commit.gpgsign", "false")
+
+	return f
+}
+
+// cleanup removes the fixture repository from disk.
+func (f *fixture) cleanup() {
+	os.RemoveAll(f.dir)
+}
+
+// git runs a git command inside the fixture, failing the test on error.
+func (f *fixture) git(args ...string) string {
+	return f.gitEnv(nil, args...)
+}
+
+func (f *fixture) gitEnv(env []string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = f.dir
+	cmd.Env = append(append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1"), env...)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		f.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// write creates or overwrites files in the working tree without committing.
+func (f *fixture) write(files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(f.dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			f.t.Fatalf("Unable to create directory for %s: %v\n", name, err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			f.t.Fatalf("Unable to write %s: %v\n", name, err)
+		}
+	}
+}
+
+// commit writes files and commits everything in the working tree as the
+// author with the given email. It returns the new commit's hash.
+func (f *fixture) commit(email string, files map[string]string) string {
+	f.clock = f.clock.Add(time.Minute)
+	return f.commitAt(email, f.clock, files)
+}
+
+// commitAt is like commit, but stamps the commit with a specific time.
+func (f *fixture) commitAt(email string, when time.Time, files map[string]string) string {
+	return f.commitAsAt(strings.SplitN(email, "@", 2)[0], email, when, files)
+}
+
+// commitAs is like commit, but with an explicit author name rather than one
+// derived from the email.
+func (f *fixture) commitAs(name, email string, files map[string]string) string {
+	f.clock = f.clock.Add(time.Minute)
+	return f.commitAsAt(name, email, f.clock, files)
+}
+
+func (f *fixture) commitAsAt(name, email string, when time.Time, files map[string]string) string {
+	f.write(files)
+	f.git("add", "-A")
+
+	date := when.Format(time.RFC3339)
+	f.gitEnv([]string{
+		"GIT_AUTHOR_NAME=" + name,
+		"GIT_AUTHOR_EMAIL=" + email,
+		"GIT_AUTHOR_DATE=" + date,
+		"GIT_COMMITTER_NAME=" + name,
+		"GIT_COMMITTER_EMAIL=" + email,
+		"GIT_COMMITTER_DATE=" + date,
+	}, "commit", "-q", "--allow-empty", "-m", "Change by "+name)
+
+	return f.git("rev-parse", "HEAD")
+}
+
+// counter opens the fixture as a ContributionCounter.
+func (f *fixture) counter() *ContributionCounter {
+	repo, err := gogit.PlainOpen(f.dir)
+	if err != nil {
+		f.t.Fatalf("Unable to open fixture repository: %v\n", err)
+	}
+
+	return &ContributionCounter{Repo: repo}
+}
+
+// gitWrapper starts every script wrapGit installs. It finds the git
+// subcommand, the first argument after git that isn't an option or the value
+// of -c, and the last argument.
+const gitWrapper = `first=1; sub=; prev=; last=
+for a; do
+	if [ -n "$first" ]; then first=
+	elif [ -z "$sub" ] && [ "$prev" != -c ] && [ "${a#-}" = "$a" ]; then sub=$a
+	fi
+	prev=$a; last=$a
+done
+`
+
+// wrapGit runs every git command the counter runs through a shell script, to
+// watch or interfere with them. The script sees the subcommand, such as
+// blame, in $sub and the command's last argument in $last, however the
+// options before them are laid out. "$@" is the whole git command, for the
+// script to finish with exec "$@", and $0 is arg, such as a file to log to.
+func wrapGit(r *ContributionCounter, arg, script string) {
+	r.CommandPrefix = []string{"sh", "-c", gitWrapper + script, arg}
+}
+
+// reviewers runs the full pipeline against the fixture's changed files.
+func (f *fixture) reviewers(r *ContributionCounter) string {
+	files, err := r.FindFiles()
+	if err != nil {
+		f.t.Fatalf("Unable to find files: %v\n", err)
+	}
+
+	out, err := r.FindReviewers(files)
+	if err != nil {
+		f.t.Fatalf("Unable to find reviewers: %v\n", err)
+	}
+
+	return out
+}
+
+// twoAuthorFixture builds a master branch where abe and george each own a
+// file, then a feature branch by the current user that touches both.
+func twoAuthorFixture(t *testing.T) *fixture {
+	f := newFixture(t)
+	f.commit("abe@git-reviewer.com", map[string]string{"main.go": "a\nb\nc\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"util.go": "d\ne\n"})
+	f.commit("me@git-reviewer.com", map[string]string{"doc.go": "f\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"main.go": "a\nb\nc\nx\n",
+		"util.go": "d\ne\ny\n",
+		"doc.go":  "f\nz\n",
+	})
+
+	return f
+}
@@ -0,0 +1,65 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// HandleResolver looks up the GitHub handle for a reviewer's email, such as
+// "octocat" for a user or "my-org/reviewers" for a team. The second result
+// reports whether the reviewer has a handle at all.
+type HandleResolver func(email string) (string, bool)
+
+// gitHubReviewRequest is the body of GitHub's request-reviewers endpoint.
+type gitHubReviewRequest struct {
+	Reviewers     []string `json:"reviewers"`
+	TeamReviewers []string `json:"team_reviewers"`
+}
+
+// GitHubFormatter writes Stats as the body of a request to GitHub's
+// POST /repos/{owner}/{repo}/pulls/{pull_number}/requested_reviewers
+// endpoint.
+type GitHubFormatter struct {
+	// Resolve maps reviewers to GitHub handles. Reviewers it has no handle
+	// for are left out, since GitHub can't request reviews by email. Without
+	// it, reviewers are assumed to already be handles.
+	Resolve HandleResolver
+}
+
+// Write encodes the payload for the reviewers to w. Handles in "org/team"
+// form are requested as teams, by their slug, and anything else as a user.
+// A leading "@", as in CODEOWNERS files, is dropped.
+func (f GitHubFormatter) Write(w io.Writer, s Stats) error {
+	payload := gitHubReviewRequest{
+		Reviewers:     []string{},
+		TeamReviewers: []string{},
+	}
+
+	seen := make(map[string]bool)
+	for _, stat := range s {
+		handle, ok := stat.Reviewer, true
+		if f.Resolve != nil {
+			handle, ok = f.Resolve(stat.Reviewer)
+		}
+
+		handle = strings.TrimPrefix(handle, "@")
+		if !ok || len(handle) == 0 || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+
+		if i := strings.Index(handle, "/"); i >= 0 {
+			payload.TeamReviewers = append(payload.TeamReviewers, handle[i+1:])
+		} else {
+			payload.Reviewers = append(payload.Reviewers, handle)
+		}
+	}
+
+	return json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,161 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+// Package github requests reviews on GitHub pull requests from the reviewers
+// gitreviewers suggests. It is kept apart from the gitreviewers package so
+// that finding reviewers doesn't depend on an HTTP client.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	gr "github.com/thedahv/git-reviewer/src"
+)
+
+// DefaultClient talks to github.com.
+var DefaultClient = &Client{BaseURL: "https://api.github.com"}
+
+// Client talks to a GitHub REST API, such as GitHub Enterprise's at
+// "https://github.example.com/api/v3".
+type Client struct {
+	// BaseURL is the root of the API, without a trailing slash.
+	BaseURL string
+
+	// HTTPClient makes the requests, defaulting to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// RequestReviewers requests reviews on a pull request with DefaultClient.
+// See Client.RequestReviewers.
+func RequestReviewers(ctx context.Context, owner, repo string, pr int, reviewers []string, token string) ([]string, error) {
+	return DefaultClient.RequestReviewers(ctx, owner, repo, pr, reviewers, token)
+}
+
+// RequestReviewers requests reviews from the reviewers on pull request pr of
+// owner/repo, authenticating with token, and returns the logins and teams it
+// requested. Reviewers are emails, as gitreviewers reports them, which are
+// looked up through the users search, or already logins: anything without
+// an "@" past its first character, or "org/team" for a team. Reviewers no
+// GitHub user can be found for are skipped, and when none are left nothing
+// is requested.
+func (c *Client) RequestReviewers(ctx context.Context, owner, repo string, pr int, reviewers []string, token string) ([]string, error) {
+	logins := make(map[string]string)
+	var found gr.Stats
+	for _, reviewer := range reviewers {
+		login, err := c.login(ctx, reviewer, token)
+		if err != nil {
+			return nil, err
+		}
+		if len(login) > 0 {
+			logins[reviewer] = login
+			found = append(found, &gr.Stat{Reviewer: reviewer})
+		}
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	// The formatter knows how GitHub wants users and teams told apart
+	var body bytes.Buffer
+	formatter := gr.GitHubFormatter{Resolve: func(email string) (string, bool) {
+		login, ok := logins[email]
+		return login, ok
+	}}
+	if err := formatter.Write(&body, found); err != nil {
+		return nil, errors.Wrap(err, "unable to encode review request")
+	}
+
+	var requested []string
+	seen := make(map[string]bool)
+	for _, stat := range found {
+		if login := logins[stat.Reviewer]; !seen[login] {
+			seen[login] = true
+			requested = append(requested, login)
+		}
+	}
+
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers",
+		c.BaseURL, url.PathEscape(owner), url.PathEscape(repo), pr)
+	if err := c.do(ctx, http.MethodPost, endpoint, token, &body, http.StatusCreated, nil); err != nil {
+		return nil, errors.Wrapf(err, "unable to request reviewers on %s/%s#%d", owner, repo, pr)
+	}
+
+	return requested, nil
+}
+
+// login finds the GitHub login for a reviewer, or nothing when there isn't
+// one. GitHub's private commit emails, "123+login@users.noreply.github.com",
+// name the login outright.
+func (c *Client) login(ctx context.Context, reviewer, token string) (string, error) {
+	reviewer = strings.TrimPrefix(reviewer, "@")
+	at := strings.LastIndex(reviewer, "@")
+	if at < 0 {
+		return reviewer, nil
+	}
+	if user, domain := reviewer[:at], reviewer[at+1:]; domain == "users.noreply.github.com" {
+		return user[strings.Index(user, "+")+1:], nil
+	}
+
+	var result struct {
+		Items []struct {
+			Login string `json:"login"`
+		} `json:"items"`
+	}
+	endpoint := c.BaseURL + "/search/users?q=" + url.QueryEscape(reviewer+" in:email")
+	if err := c.do(ctx, http.MethodGet, endpoint, token, nil, http.StatusOK, &result); err != nil {
+		return "", errors.Wrapf(err, "unable to look up the GitHub user for %s", reviewer)
+	}
+	if len(result.Items) == 0 {
+		return "", nil
+	}
+
+	return result.Items[0].Login, nil
+}
+
+// do sends a request to the API and decodes the response into result, if
+// given, when it has the expected status.
+func (c *Client) do(ctx context.Context, method, endpoint, token string, body io.Reader, expected int, result interface{}) error {
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "token "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expected {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("GitHub responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
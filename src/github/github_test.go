@@ -0,0 +1,89 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRequestReviewers(t *testing.T) {
+	users := map[string]string{"abe@git-reviewer.com in:email": "abe-gh"}
+
+	var (
+		payload map[string][]string
+		auth    string
+		posts   int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/users", func(w http.ResponseWriter, req *http.Request) {
+		var items []map[string]string
+		if login, ok := users[req.URL.Query().Get("q")]; ok {
+			items = append(items, map[string]string{"login": login})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"total_count": len(items), "items": items})
+	})
+	mux.HandleFunc("/repos/octo/app/pulls/7/requested_reviewers", func(w http.ResponseWriter, req *http.Request) {
+		posts++
+		if req.Method != http.MethodPost {
+			t.Errorf("Got a %s request for reviewers, expected POST\n", req.Method)
+		}
+		auth = req.Header.Get("Authorization")
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("Unable to decode payload: %v\n", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "{}")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL}
+	requested, err := c.RequestReviewers(context.Background(), "octo", "app", 7, []string{
+		"abe@git-reviewer.com",
+		"nobody@git-reviewer.com",
+		"123+tom@users.noreply.github.com",
+		"@org/web-team",
+	}, "secret")
+	if err != nil {
+		t.Fatalf("Unexpected error requesting reviewers: %v\n", err)
+	}
+
+	if expected := []string{"abe-gh", "tom", "org/web-team"}; !reflect.DeepEqual(requested, expected) {
+		t.Errorf("Got requested %v, expected %v\n", requested, expected)
+	}
+	expected := map[string][]string{"reviewers": {"abe-gh", "tom"}, "team_reviewers": {"web-team"}}
+	if !reflect.DeepEqual(payload, expected) {
+		t.Errorf("Got payload %v, expected %v\n", payload, expected)
+	}
+	if auth != "token secret" {
+		t.Errorf("Got authorization '%s', expected the token\n", auth)
+	}
+
+	// Nobody to request means no request at all
+	requested, err = c.RequestReviewers(context.Background(), "octo", "app", 7,
+		[]string{"nobody@git-reviewer.com"}, "secret")
+	if err != nil || len(requested) != 0 || posts != 1 {
+		t.Errorf("Got requested %v and error %v after %d requests, expected none\n", requested, err, posts)
+	}
+}
+
+func TestRequestReviewersRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, `{"message":"Reviews may only be requested from collaborators."}`, http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL}
+	if _, err := c.RequestReviewers(context.Background(), "octo", "app", 7, []string{"tom"}, ""); err == nil {
+		t.Errorf("Expected an error when GitHub rejects the request\n")
+	}
+}
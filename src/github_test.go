@@ -0,0 +1,55 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGitHubFormatter(t *testing.T) {
+	handles := map[string]string{
+		"abe@git-reviewer.com":    "abe",
+		"george@git-reviewer.com": "@git-reviewer/core",
+		"jane@git-reviewer.com":   "jane-gh",
+		"bob@git-reviewer.com":    "abe",
+	}
+	resolve := func(email string) (string, bool) {
+		h, ok := handles[email]
+		return h, ok
+	}
+
+	s := Stats{
+		&Stat{Reviewer: "abe@git-reviewer.com"},
+		&Stat{Reviewer: "george@git-reviewer.com"},
+		&Stat{Reviewer: "nobody@git-reviewer.com"},
+		&Stat{Reviewer: "jane@git-reviewer.com"},
+		&Stat{Reviewer: "bob@git-reviewer.com"},
+	}
+
+	var buf bytes.Buffer
+	if err := (GitHubFormatter{Resolve: resolve}).Write(&buf, s); err != nil {
+		t.Fatalf("Unexpected error writing payload: %v\n", err)
+	}
+
+	expected := `{"reviewers":["abe","jane-gh"],"team_reviewers":["core"]}` + "\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Got payload %s, expected %s", actual, expected)
+	}
+}
+
+func TestGitHubFormatterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (GitHubFormatter{}).Write(&buf, nil); err != nil {
+		t.Fatalf("Unexpected error writing payload: %v\n", err)
+	}
+
+	// GitHub rejects null where it expects a list
+	expected := `{"reviewers":[],"team_reviewers":[]}` + "\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Got payload %s, expected %s", actual, expected)
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	gogit "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// gitCommand builds an external git command that runs against the counter's
+// repository rather than whatever directory the process happens to be in.
+// Any CommandPrefix runs first, with git and its arguments passed to it as
+// separate arguments. Paths in the output are never quoted, so they match the
+// literal UTF-8 paths go-git reports. The command is killed if the counter's
+// context ends while it runs.
+func (r *ContributionCounter) gitCommand(args ...string) *exec.Cmd {
+	argv := append(append([]string{}, r.CommandPrefix...), "git", "-c", "core.quotePath=false")
+	argv = append(argv, args...)
+
+	cmd := exec.CommandContext(r.context(), argv[0], argv[1:]...)
+	cmd.Dir = r.repoDir()
+	r.count(func(m *Metrics) { m.GitCommands++ })
+
+	return cmd
+}
+
+// git runs an external git command against the counter's repository and
+// returns its output with surrounding whitespace trimmed.
+func (r *ContributionCounter) git(args ...string) ([]byte, error) {
+	out, err := r.output(r.gitCommand(args...))
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(out), nil
+}
+
+// repoDir finds the root of the working tree for the counter's repository.
+// An empty result means git commands run in the current directory.
+func (r *ContributionCounter) repoDir() string {
+	if len(r.WorkDir) > 0 {
+		return r.WorkDir
+	}
+
+	if r.Repo == nil {
+		return ""
+	}
+
+	wt, err := r.Repo.Worktree()
+	if err != nil {
+		return ""
+	}
+
+	return wt.Filesystem.Root()
+}
+
+// prepare readies the counter for use so that a zero-value ContributionCounter
+// works out of the box. When no Repo was given, the repository in WorkDir or
+// the current directory is opened.
+func (r *ContributionCounter) prepare() error {
+	if r == nil {
+		return ErrNilCounter
+	}
+
+	if r.Repo == nil {
+		dir := r.WorkDir
+		if len(dir) == 0 {
+			var err error
+			if dir, err = os.Getwd(); err != nil {
+				return errors.Wrap(err, "unable to open current directory")
+			}
+		}
+
+		repo, err := gogit.PlainOpen(dir)
+		if err == gogit.ErrRepositoryNotExists {
+			return ErrNoRepository
+		} else if err != nil {
+			return errors.Wrap(err, "unable to open repository")
+		}
+		r.Repo = repo
+	}
+
+	if err := r.checkRepo(); err != nil {
+		return err
+	}
+
+	if !r.IgnoreMailmap {
+		r.addRepoMailmap()
+	}
+
+	return nil
+}
+
+// checkRepo makes sure the repository has history to work with. A freshly
+// initialized repository has no HEAD commit, which would otherwise surface as a
+// cryptic error from whichever git operation happens to run first.
+func (r *ContributionCounter) checkRepo() error {
+	if c, ok := r.backend().(interface {
+		check(*ContributionCounter) error
+	}); ok {
+		if err := c.check(r); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.backend().Resolve(r, "HEAD"); err != nil {
+		return ErrEmptyRepository
+	}
+
+	return nil
+}
+
+// base returns the configured base revision, defaulting to the remote's
+// default branch and then to "master". The remote is only asked about once a
+// run, and not before the counter has a repository.
+func (r *ContributionCounter) base() string {
+	if len(r.BaseBranch) > 0 {
+		return r.BaseBranch
+	}
+	if r.Repo == nil {
+		return "master"
+	}
+
+	return r.perRun("base", func() string {
+		if !r.runsGit() {
+			ref, err := r.Repo.Reference(originHead, false)
+			if err == nil && ref.Type() == plumbing.SymbolicReference {
+				return ref.Target().Short()
+			}
+
+			return "master"
+		}
+
+		out, err := r.git("symbolic-ref", "-q", "--short", originHead.String())
+		if ref := string(bytes.TrimSpace(out)); err == nil && len(ref) > 0 {
+			return ref
+		}
+
+		return "master"
+	})
+}
+
+// originHead points at the remote's default branch.
+const originHead = plumbing.ReferenceName("refs/remotes/origin/HEAD")
+
+// baseCommit resolves the base to a commit. Branches, tags (annotated or
+// not), and arbitrary revisions are all treated the same way by letting the
+// Backend verify and peel them.
+func (r *ContributionCounter) baseCommit() (*object.Commit, error) {
+	base := r.base()
+	h, err := r.backend().Resolve(r, base)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to resolve base '%s'", base)
+	}
+
+	return r.Repo.CommitObject(h)
+}
+
+// gitConfig reads a setting such as user.email from git config. Without the
+// git binary, only the repository's own config is read.
+func (r *ContributionCounter) gitConfig(name string) (string, error) {
+	if r.runsGit() {
+		out, err := r.git("config", name)
+		return string(out), err
+	}
+
+	cfg, err := r.Repo.Config()
+	if err != nil {
+		return "", err
+	}
+
+	dot := strings.LastIndex(name, ".")
+	if v := cfg.Raw.Section(name[:dot]).Option(name[dot+1:]); len(v) > 0 {
+		return v, nil
+	}
+
+	return "", errors.Errorf("%s is not set", name)
+}
+
+// headCommit resolves HEAD to a commit, as baseCommit does the base.
+func (r *ContributionCounter) headCommit() (*object.Commit, error) {
+	h, err := r.backend().Resolve(r, "HEAD")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to resolve HEAD")
+	}
+
+	return r.Repo.CommitObject(h)
+}
+
+// BaseIsAncestor reports whether the base is in the history of HEAD. When it
+// isn't, the branch didn't start from it and the diff against it includes
+// changes that were never made on the branch.
+func (r *ContributionCounter) BaseIsAncestor() (bool, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return false, err
+	}
+
+	base, err := r.baseCommit()
+	if err != nil {
+		return false, err
+	}
+
+	if !r.runsGit() {
+		head, err := r.headCommit()
+		if err != nil {
+			return false, err
+		}
+
+		goGitMu.Lock()
+		defer goGitMu.Unlock()
+		return base.IsAncestor(head)
+	}
+
+	// Exit status 1 is the answer "no"; anything else is a failure
+	_, err = r.git("merge-base", "--is-ancestor", base.Hash.String(), "HEAD")
+	if exit, ok := err.(*exec.ExitError); ok && exit.ExitCode() == 1 {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "unable to execute external git merge-base command")
+	}
+
+	return true, nil
+}
+
+// hasMergeBase reports whether the base and HEAD share any history. They
+// don't when the base is unrelated, or in a shallow clone that stops short of
+// where they meet.
+func (r *ContributionCounter) hasMergeBase() (bool, error) {
+	base, err := r.baseCommit()
+	if err != nil {
+		return false, err
+	}
+
+	if !r.runsGit() {
+		head, err := r.headCommit()
+		if err != nil {
+			return false, err
+		}
+
+		goGitMu.Lock()
+		defer goGitMu.Unlock()
+		found, err := base.MergeBase(head)
+		return len(found) > 0, err
+	}
+
+	_, err = r.git("merge-base", base.Hash.String(), "HEAD")
+	if exit, ok := err.(*exec.ExitError); ok && exit.ExitCode() == 1 {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "unable to execute external git merge-base command")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,531 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmptyRepository(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+
+	if _, err := r.BranchBehind(); err != ErrEmptyRepository {
+		t.Errorf("BranchBehind returned '%v', expected ErrEmptyRepository\n", err)
+	}
+
+	if _, err := r.FindFiles(); err != ErrEmptyRepository {
+		t.Errorf("FindFiles returned '%v', expected ErrEmptyRepository\n", err)
+	}
+
+	if _, err := r.FindCommitFiles([]string{"HEAD"}); err != ErrEmptyRepository {
+		t.Errorf("FindCommitFiles returned '%v', expected ErrEmptyRepository\n", err)
+	}
+
+	if _, err := r.FindReviewers([]string{"main.go"}); err != ErrEmptyRepository {
+		t.Errorf("FindReviewers returned '%v', expected ErrEmptyRepository\n", err)
+	}
+
+	if _, err := r.QuorumReviewers(2); err != ErrEmptyRepository {
+		t.Errorf("QuorumReviewers returned '%v', expected ErrEmptyRepository\n", err)
+	}
+}
+
+func TestTagAsBase(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "a\n", "b.go": "b\n"})
+	f.git("tag", "-a", "-m", "Release", "v1.0.0")
+	f.commit("george@git-reviewer.com", map[string]string{"b.go": "b\nb\n"})
+	f.git("tag", "v1.1.0")
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": "a\na\n"})
+
+	cases := []struct {
+		Base  string
+		Files []string
+	}{
+		{"v1.0.0", []string{"a.go", "b.go"}},
+		{"v1.1.0", []string{"a.go"}},
+		{"master", []string{"a.go"}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.BaseBranch = c.Base
+
+		files, err := r.FindFiles()
+		if err != nil {
+			t.Errorf("Unexpected error finding files against %s: %v\n", c.Base, err)
+			continue
+		}
+
+		sort.Strings(files)
+		if !reflect.DeepEqual(files, c.Files) {
+			t.Errorf("Got files %v against %s, expected %v\n", files, c.Base, c.Files)
+		}
+
+		if _, err := r.FindReviewers(files); err != nil {
+			t.Errorf("Unexpected error finding reviewers against %s: %v\n", c.Base, err)
+		}
+	}
+}
+
+func TestMissingBase(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	r.BaseBranch = "v9.9.9"
+
+	if _, err := r.FindFiles(); err == nil {
+		t.Error("Expected an error finding files against a missing base")
+	}
+	// The failure surfaces, rather than passing for "up to date"
+	behind, err := r.BranchBehind()
+	if err == nil || behind {
+		t.Errorf("Got behind %v and error %v comparing against a missing base\n", behind, err)
+	} else if !strings.Contains(err.Error(), "v9.9.9") {
+		t.Errorf("Got error '%v', expected it to name the missing base\n", err)
+	}
+}
+
+func TestCommandPrefix(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	r.CommandPrefix = []string{"nice", "-n", "10"}
+
+	cmd := r.gitCommand("log", "--format=%an <%ae>")
+	expected := []string{"nice", "-n", "10", "git", "-c", "core.quotePath=false", "log", "--format=%an <%ae>"}
+	if !reflect.DeepEqual(cmd.Args, expected) {
+		t.Errorf("Got command %q, expected %q\n", cmd.Args, expected)
+	}
+
+	// The wrapper logs its first argument, which has a space in it, before
+	// running git with the rest.
+	log := filepath.Join(f.dir, ".git", "prefix.log")
+	r.CommandPrefix = []string{
+		"sh", "-c", `printf '%s\n' "$1" >> "$0"; shift; exec "$@"`, log, "two words",
+	}
+
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	if _, err := r.FindReviewers(files); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	out, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatalf("Wrapper never ran: %v\n", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < len(files) {
+		t.Errorf("Wrapper ran %d times, expected at least once per file\n", len(lines))
+	}
+	for _, line := range lines {
+		if line != "two words" {
+			t.Errorf("Wrapper got argument '%s', expected 'two words'\n", line)
+		}
+	}
+}
+
+func TestSparseCheckout(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"keep/a.go": "1\n2\n"})
+	// A file with the same name as a branch can't be told apart from it
+	// when it isn't on disk
+	f.commit("george@git-reviewer.com", map[string]string{"gone/b.go": "1\n", "feature": "1\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"keep/a.go": "1\n2\n3\n",
+		"gone/b.go": "1\n2\n",
+		"feature":   "1\n2\n",
+	})
+
+	// Only keep/ is left on disk, but all the history is still there
+	f.git("sparse-checkout", "set", "--no-cone", "/keep/")
+	for _, p := range []string{"gone/b.go", "feature"} {
+		if _, err := os.Stat(filepath.Join(f.dir, p)); !os.IsNotExist(err) {
+			t.Fatalf("Expected %s to be missing from the working tree: %v\n", p, err)
+		}
+	}
+
+	for _, weight := range []Weight{WeightBlame, WeightCommits} {
+		r := f.counter()
+		r.Weight = weight
+		r.ActivityWeight = true
+
+		files, err := r.FindFiles()
+		if err != nil {
+			t.Fatalf("Unexpected error finding files: %v\n", err)
+		}
+		sort.Strings(files)
+		if expected := []string{"feature", "gone/b.go", "keep/a.go"}; !reflect.DeepEqual(files, expected) {
+			t.Errorf("Got files %v, expected %v\n", files, expected)
+		}
+
+		stats, err := r.FindReviewerStats(files)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers with weight %d: %v\n", weight, err)
+		}
+		if len(stats) != 2 {
+			t.Errorf("Got reviewers %v with weight %d, expected abe and george\n", stats, weight)
+		}
+	}
+}
+
+func TestNonASCIIPaths(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// Git quotes paths like these as "src/\303\251t\303\251.go" by default
+	f.git("config", "core.quotePath", "true")
+	f.commit("abe@git-reviewer.com", map[string]string{"src/été.go": "1\n2\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"src/été.go": "1\n2\n3\n", "src/naïve.go": "1\n"})
+
+	r := f.counter()
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	if expected := []string{"src/été.go"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Got files %q, expected %q\n", files, expected)
+	}
+
+	records, err := r.ContributionRecords(files)
+	if err != nil {
+		t.Fatalf("Unexpected error reading records: %v\n", err)
+	}
+	if len(records) != 1 || records[0].File != "src/été.go" {
+		t.Errorf("Got records %+v, expected one for src/été.go\n", records)
+	}
+
+	r.Weight = WeightCommits
+	r.ActivityWeight = true
+	stats, err := r.FindReviewerStats(files)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if len(stats) != 1 || stats[0].Percentage != 1 {
+		t.Errorf("Got reviewers %v, expected abe with all the experience\n", stats)
+	}
+
+	introduced, err := r.FindIntroducedFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding introduced files: %v\n", err)
+	}
+	if expected := []string{"src/naïve.go"}; !reflect.DeepEqual(introduced, expected) {
+		t.Errorf("Got introduced files %q, expected %q\n", introduced, expected)
+	}
+}
+
+func TestBaseBranch(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "a\n"})
+	f.git("checkout", "-q", "-b", "develop")
+	f.commit("george@git-reviewer.com", map[string]string{"b.go": "b\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"b.go": "b\nb\n"})
+
+	r := f.counter()
+	if base := r.base(); base != "master" {
+		t.Errorf("Got base '%s' without a remote, expected master\n", base)
+	}
+
+	// A clone's origin/HEAD names the remote's default branch
+	f.git("update-ref", "refs/remotes/origin/main", "develop")
+	f.git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	if base := r.base(); base != "origin/main" {
+		t.Errorf("Got base '%s', expected origin/main\n", base)
+	}
+
+	// The wrapper logs every command it runs
+	log := filepath.Join(f.dir, ".git", "commands.log")
+	r = f.counter()
+	r.BaseBranch = "develop"
+	wrapGit(r, log, `printf '%s\n' "$*" >> "$0"; exec "$@"`)
+
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	if expected := []string{"b.go"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Got files %v against develop, expected %v\n", files, expected)
+	}
+
+	out, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatalf("Wrapper never ran: %v\n", err)
+	}
+	if !strings.Contains(string(out), "develop") {
+		t.Errorf("Expected git commands to name develop, got:\n%s", out)
+	}
+}
+
+func TestPathsWithSpaces(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"foo bar/baz.go": "1\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"foo bar/baz.go": "1\n2\n"})
+
+	// The wrapper logs the last argument of each blame
+	log := filepath.Join(f.dir, ".git", "blame.log")
+	r := f.counter()
+	wrapGit(r, log, `[ "$sub" = blame ] && echo "$last" >> "$0"; exec "$@"`)
+
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	if expected := []string{"foo bar/baz.go"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Got files %q, expected %q\n", files, expected)
+	}
+
+	stats, err := r.FindReviewerStats(files)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if len(stats) != 1 || stats[0].Reviewer != "abe@git-reviewer.com" {
+		t.Errorf("Got reviewers %v, expected abe\n", stats)
+	}
+
+	out, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatalf("Unable to read blame log: %v\n", err)
+	}
+	if blamed := strings.TrimSpace(string(out)); blamed != "foo bar/baz.go" {
+		t.Errorf("Blamed '%s', expected the path as a single argument\n", blamed)
+	}
+}
+
+func TestBranchBehind(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	// A branch that is missing master's last commit and has nothing new
+	f.git("branch", "stale", "master~1")
+
+	// Master moves on after feature branched, with an older commit date than
+	// feature's tip, so comparing dates would think feature was up to date
+	f.git("checkout", "-q", "-b", "moved", "master")
+	f.commitAt("abe@git-reviewer.com", f.clock.Add(-time.Hour), map[string]string{"main.go": "a\n"})
+
+	// An unrelated history has nothing to merge up with
+	f.git("checkout", "-q", "--orphan", "other")
+	f.commit("bob@git-reviewer.com", map[string]string{"other.go": "1\n"})
+	f.git("checkout", "-q", "feature")
+
+	cases := []struct {
+		Head, Base string
+		Behind     bool
+	}{
+		{"feature", "feature", false},
+		{"feature", "master", false},
+		{"stale", "master", true},
+		{"feature", "moved", true},
+		{"feature", "other", false},
+	}
+
+	for _, c := range cases {
+		f.git("checkout", "-q", c.Head)
+
+		var warned []string
+		r := f.counter()
+		r.BaseBranch = c.Base
+		r.OnUnrelatedBase = func(base string) { warned = append(warned, base) }
+
+		behind, err := r.BranchBehind()
+		if err != nil {
+			t.Fatalf("Unexpected error comparing %s with %s: %v\n", c.Head, c.Base, err)
+		}
+		if behind != c.Behind {
+			t.Errorf("Got behind %v for %s against %s, expected %v\n", behind, c.Head, c.Base, c.Behind)
+		}
+		if len(warned) > 0 {
+			t.Errorf("Got warnings %v for %s against %s, expected FindFiles to give them\n",
+				warned, c.Head, c.Base)
+		}
+	}
+}
+
+func TestBaseIsAncestor(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	// An unrelated history, as if someone fetched another project
+	f.git("checkout", "-q", "--orphan", "other")
+	f.commit("bob@git-reviewer.com", map[string]string{"other.go": "1\n"})
+	f.git("checkout", "-q", "feature")
+
+	cases := []struct {
+		Base     string
+		Ancestor bool
+	}{
+		{"master", true},
+		{"feature", true},
+		{"other", false},
+	}
+
+	for _, c := range cases {
+		var warned []string
+		r := f.counter()
+		r.BaseBranch = c.Base
+		r.OnUnrelatedBase = func(base string) { warned = append(warned, base) }
+
+		ok, err := r.BaseIsAncestor()
+		if err != nil {
+			t.Fatalf("Unexpected error checking %s: %v\n", c.Base, err)
+		}
+		if ok != c.Ancestor {
+			t.Errorf("Got ancestor %v for %s, expected %v\n", ok, c.Base, c.Ancestor)
+		}
+
+		if _, err := r.FindFiles(); err != nil {
+			t.Fatalf("Unexpected error finding files against %s: %v\n", c.Base, err)
+		}
+		if c.Ancestor && len(warned) > 0 {
+			t.Errorf("Got warnings %v against %s, expected none\n", warned, c.Base)
+		} else if !c.Ancestor && !reflect.DeepEqual(warned, []string{c.Base}) {
+			t.Errorf("Got warnings %v against %s, expected one about it\n", warned, c.Base)
+		}
+	}
+
+	r := f.counter()
+	r.BaseBranch = "v9.9.9"
+	if _, err := r.BaseIsAncestor(); err == nil {
+		t.Error("Expected an error checking a missing base")
+	}
+}
+
+func TestVerbose(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	var log bytes.Buffer
+	r := f.counter()
+	r.Verbose = true
+	r.Log = &log
+
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	if _, err := r.FindReviewers(files); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	// Every blame is logged, ready to paste into a shell
+	for _, p := range files {
+		line := "+ git -c core.quotePath=false blame -cel -M "
+		if !strings.Contains(log.String(), line) || !strings.Contains(log.String(), " -- "+p+"\n") {
+			t.Errorf("Expected the blame of %s to be logged, got:\n%s", p, log.String())
+		}
+	}
+
+	// So are failures, with what git had to say about them
+	log.Reset()
+	r.BaseBranch = "v9.9.9"
+	if _, err := r.FindFiles(); err == nil {
+		t.Fatal("Expected an error finding files against a missing base")
+	}
+	if !strings.Contains(log.String(), "git failed: exit status") {
+		t.Errorf("Expected the failure to be logged, got:\n%s", log.String())
+	}
+
+	// Quiet by default
+	log.Reset()
+	r = f.counter()
+	r.Log = &log
+	if _, err := r.FindFiles(); err != nil || log.Len() > 0 {
+		t.Errorf("Got error %v and log output:\n%s\nexpected neither\n", err, log.String())
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	// A shell given the logged line sees the arguments that were run
+	args := []string{`%s\n`, "$HOME `id` !! it's", "src/café/naïve file.go", "*.go", ""}
+	line := shellJoin(append([]string{"printf"}, args...))
+
+	out, err := exec.Command("sh", "-c", line).Output()
+	if err != nil {
+		t.Fatalf("Unable to run %s: %v\n", line, err)
+	}
+	if expected := strings.Join(args[1:], "\n") + "\n"; string(out) != expected {
+		t.Errorf("Got output %q from %s, expected %q\n", out, line, expected)
+	}
+}
+
+func TestShallowClone(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	// Move the base on, so a shallow clone doesn't reach a shared commit
+	f.git("checkout", "-q", "master")
+	f.commit("bob@git-reviewer.com", map[string]string{"util.go": "d\ne\nw\n"})
+	f.git("checkout", "-q", "feature")
+
+	dir, err := ioutil.TempDir("", "git-reviewer")
+	if err != nil {
+		t.Fatalf("Unable to create clone directory: %v\n", err)
+	}
+	clone := &fixture{t: t, dir: dir}
+	defer clone.cleanup()
+	f.git("clone", "-q", "--depth", "1", "--no-single-branch", "--branch", "feature",
+		"file://"+f.dir, clone.dir)
+	if err := exec.Command("git", "-C", clone.dir, "merge-base", "origin/master", "HEAD").Run(); err == nil {
+		t.Fatal("Expected the shallow clone to have no merge base")
+	}
+
+	var warned []string
+	r := clone.counter()
+	r.BaseBranch = "origin/master"
+	r.OnUnrelatedBase = func(base string) { warned = append(warned, base) }
+
+	// Both sides of the two-dot diff count
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	sort.Strings(files)
+	if expected := []string{"doc.go", "main.go", "util.go"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Got files %v, expected %v\n", files, expected)
+	}
+	if !reflect.DeepEqual(warned, []string{"origin/master"}) {
+		t.Errorf("Got warnings %v, expected one about origin/master\n", warned)
+	}
+
+	// History stops at the graft, so the base commit gets all the credit
+	stats, err := r.FindReviewerStats(files)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if len(stats) != 1 || stats[0].Reviewer != "bob@git-reviewer.com" {
+		t.Errorf("Got reviewers %v, expected bob's grafted commit\n", stats)
+	}
+}
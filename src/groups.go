@@ -0,0 +1,182 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ReviewersByExtension groups the changed paths by file extension and finds
+// the top reviewers for each group separately, since different kinds of files
+// (frontend vs backend, code vs docs) often have different experts. Groups are
+// keyed by lowercase extension without the dot; files without an extension
+// are grouped under "". Groups where nobody qualifies have no Stats.
+func (r *ContributionCounter) ReviewersByExtension(paths []string) (map[string]Stats, error) {
+	defer r.startRun()()
+	return r.reviewersByGroup(paths, extOf)
+}
+
+// ReviewersByDir groups the changed paths by the directory they are directly
+// in and finds the top reviewers for each group separately, like
+// ReviewersByExtension. Files at the root of the repository are grouped
+// under ".".
+func (r *ContributionCounter) ReviewersByDir(paths []string) (map[string]Stats, error) {
+	defer r.startRun()()
+	return r.reviewersByGroup(paths, path.Dir)
+}
+
+// reviewersByGroup finds the top reviewers for each group of paths keyOf
+// puts together.
+func (r *ContributionCounter) reviewersByGroup(paths []string, keyOf func(string) string) (map[string]Stats, error) {
+	groups := make(map[string][]string)
+	for _, p := range paths {
+		key := keyOf(p)
+		groups[key] = append(groups[key], p)
+	}
+
+	byKey := make(map[string]Stats)
+	for key, files := range groups {
+		stats, err := r.FindReviewerStats(files)
+		if _, ok := err.(NoReviewersErr); err != nil && !ok {
+			return nil, err
+		}
+
+		byKey[key] = stats
+	}
+
+	return byKey, nil
+}
+
+// Group is the reviewers found for one group of changed paths.
+type Group struct {
+	Key   string
+	Stats Stats
+}
+
+// SortedGroups lists the groups from ReviewersByExtension, ReviewersByDir, or
+// ReviewerTrend in order of key, so output built from them is the same from
+// run to run. Trend windows given as "YYYY-MM-DD" dates sort by when they
+// start.
+func SortedGroups(groups map[string]Stats) []Group {
+	sorted := make([]Group, 0, len(groups))
+	for key, stats := range groups {
+		sorted = append(sorted, Group{Key: key, Stats: stats})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	return sorted
+}
+
+// DominantExtension finds the extension most of the changed paths share, which
+// in a polyglot repository hints at which team's reviewer pool a change
+// belongs to. When extensions are tied on file count, the one with more lines
+// changed between the base and HEAD wins. Extensions are keyed as in
+// ReviewersByExtension.
+func (r *ContributionCounter) DominantExtension(paths []string) (string, error) {
+	defer r.startRun()()
+
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	files := make(map[string]int)
+	for _, p := range paths {
+		files[extOf(p)]++
+	}
+
+	churn, err := r.branchChurn(paths)
+	if err != nil {
+		return "", err
+	}
+
+	var exts []string
+	for ext := range files {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool {
+		a, b := exts[i], exts[j]
+		if files[a] != files[b] {
+			return files[a] > files[b]
+		}
+		if churn[a] != churn[b] {
+			return churn[a] > churn[b]
+		}
+		return a < b
+	})
+
+	return exts[0], nil
+}
+
+// branchChurn totals the lines added and deleted per extension between the
+// base and HEAD.
+func (r *ContributionCounter) branchChurn(paths []string) (map[string]int64, error) {
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	churn := make(map[string]int64)
+	if !r.runsGit() {
+		head, err := r.headCommit()
+		if err != nil {
+			return nil, err
+		}
+
+		from, err := base.Tree()
+		if err != nil {
+			return nil, err
+		}
+		to, err := head.Tree()
+		if err != nil {
+			return nil, err
+		}
+
+		stats, err := treeStats(from, to, paths)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to diff the branch against the base")
+		}
+		for _, stat := range stats {
+			churn[extOf(stat.Name)] += int64(stat.Addition + stat.Deletion)
+		}
+		return churn, nil
+	}
+
+	args := append([]string{"diff", "--numstat", base.Hash.String(), "HEAD", "--"}, paths...)
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git diff command")
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		added, _ := strconv.ParseInt(fields[0], 10, 64)
+		deleted, _ := strconv.ParseInt(fields[1], 10, 64)
+		churn[extOf(fields[2])] += added + deleted
+	}
+
+	return churn, nil
+}
+
+// extOf returns the lowercase extension of a path without the dot.
+func extOf(path string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+}
@@ -0,0 +1,161 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReviewersByExtension(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("gopher@git-reviewer.com", map[string]string{
+		"server.go": "1\n2\n3\n", "client.go": "1\n2\n",
+	})
+	f.commit("frontend@git-reviewer.com", map[string]string{
+		"app.js": "1\n2\n3\n", "Makefile": "all:\n",
+	})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"server.go": "x\n", "client.go": "x\n", "app.js": "x\n", "Makefile": "x\n",
+	})
+
+	r := f.counter()
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unable to find files: %v\n", err)
+	}
+
+	byExt, err := r.ReviewersByExtension(files)
+	if err != nil {
+		t.Fatalf("Unexpected error grouping reviewers: %v\n", err)
+	}
+
+	cases := []struct {
+		Ext, Reviewer string
+	}{
+		{"go", "gopher@git-reviewer.com"},
+		{"js", "frontend@git-reviewer.com"},
+		{"", "frontend@git-reviewer.com"},
+	}
+
+	if l := len(byExt); l != len(cases) {
+		t.Errorf("Got %d groups, expected %d\n", l, len(cases))
+	}
+
+	for _, c := range cases {
+		stats := byExt[c.Ext]
+		if len(stats) != 1 {
+			t.Errorf("Got %d reviewers for '%s', expected 1\n", len(stats), c.Ext)
+			continue
+		}
+
+		if stats[0].Reviewer != c.Reviewer || stats[0].Percentage != 1 {
+			t.Errorf("Got %s with %.2f for '%s', expected %s with 1.00\n",
+				stats[0].Reviewer, stats[0].Percentage, c.Ext, c.Reviewer)
+		}
+	}
+}
+
+func TestDominantExtension(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"a.go": "1\n", "b.go": "1\n", "c.go": "1\n", "app.js": "1\n", "lib.js": "1\n",
+	})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"a.go": "2\n", "b.go": "2\n", "c.go": "2\n",
+		"app.js": "1\n2\n3\n4\n5\n6\n", "lib.js": "1\n2\n3\n4\n5\n6\n",
+	})
+
+	cases := []struct {
+		Paths    []string
+		Expected string
+	}{
+		// More Go files, despite more JavaScript churn
+		{[]string{"a.go", "b.go", "c.go", "app.js", "lib.js"}, "go"},
+		// Tied on files, so churn decides
+		{[]string{"a.go", "B.GO", "app.js", "lib.js"}, "js"},
+		{[]string{"a.go", "app.js"}, "js"},
+		{nil, ""},
+	}
+
+	for _, c := range cases {
+		ext, err := f.counter().DominantExtension(c.Paths)
+		if err != nil {
+			t.Errorf("Unexpected error for %v: %v\n", c.Paths, err)
+		} else if ext != c.Expected {
+			t.Errorf("Got dominant extension '%s' for %v, expected '%s'\n",
+				ext, c.Paths, c.Expected)
+		}
+	}
+}
+
+func TestReviewersByDir(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("root@git-reviewer.com", map[string]string{"main.go": "1\n2\n"})
+	f.commit("cmd@git-reviewer.com", map[string]string{"cmd/tool/main.go": "1\n2\n"})
+	f.commit("lib@git-reviewer.com", map[string]string{"lib/a.go": "1\n", "lib/b.go": "2\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"main.go": "x\n", "cmd/tool/main.go": "x\n", "lib/a.go": "x\n", "lib/b.go": "x\n",
+	})
+
+	r := f.counter()
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unable to find files: %v\n", err)
+	}
+
+	byDir, err := r.ReviewersByDir(files)
+	if err != nil {
+		t.Fatalf("Unexpected error grouping reviewers: %v\n", err)
+	}
+
+	expected := map[string]string{
+		".":        "root@git-reviewer.com",
+		"cmd/tool": "cmd@git-reviewer.com",
+		"lib":      "lib@git-reviewer.com",
+	}
+	if l := len(byDir); l != len(expected) {
+		t.Errorf("Got %d groups, expected %d\n", l, len(expected))
+	}
+	for dir, reviewer := range expected {
+		if stats := byDir[dir]; len(stats) != 1 || stats[0].Reviewer != reviewer {
+			t.Errorf("Got %v for '%s', expected %s\n", stats, dir, reviewer)
+		}
+	}
+}
+
+func TestSortedGroups(t *testing.T) {
+	abe := Stats{&Stat{Reviewer: "abe@git-reviewer.com"}}
+	george := Stats{&Stat{Reviewer: "george@git-reviewer.com"}}
+	groups := map[string]Stats{"lib": abe, ".": george, "cmd/tool": abe, "cmd": nil}
+
+	expected := []Group{
+		{Key: ".", Stats: george},
+		{Key: "cmd", Stats: nil},
+		{Key: "cmd/tool", Stats: abe},
+		{Key: "lib", Stats: abe},
+	}
+
+	// Map iteration order changes from run to run, so check it holds up
+	for i := 0; i < 20; i++ {
+		if actual := SortedGroups(groups); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Got groups %v, expected %v\n", actual, expected)
+		}
+	}
+
+	if actual := SortedGroups(nil); len(actual) != 0 {
+		t.Errorf("Got groups %v for no groups, expected none\n", actual)
+	}
+}
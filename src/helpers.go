@@ -9,7 +9,9 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 )
 
 // runGuard supports programming with the "sticky errors" pattern, allowing
@@ -63,6 +65,39 @@ func readMailmap(paths []string) (mailmap, error) {
 	return mm, nil
 }
 
+// addRepoMailmap reads the repository's own .mailmap, from the working tree
+// or, in bare repositories, as of HEAD, and adds what it says to the mailmap.
+// Entries already in the mailmap, as from BuildMailmap, take precedence.
+// Git's blame output has the file applied already, but emails from git log
+// and go-git don't.
+func (r *ContributionCounter) addRepoMailmap() {
+	var data []byte
+	if wt, err := r.Repo.Worktree(); err == nil {
+		data, _ = ioutil.ReadFile(filepath.Join(wt.Filesystem.Root(), ".mailmap"))
+	} else if head, err := r.Repo.Head(); err == nil {
+		if c, err := r.Repo.CommitObject(head.Hash()); err == nil {
+			if f, err := c.File(".mailmap"); err == nil {
+				contents, _ := f.Contents()
+				data = []byte(contents)
+			}
+		}
+	}
+	if len(data) == 0 {
+		return
+	}
+
+	repo := make(mailmap)
+	readMailmapFromSource(repo, bytes.NewReader(data))
+	if r.Mailmap == nil {
+		r.Mailmap = make(mailmap)
+	}
+	for from, to := range repo {
+		if _, ok := r.Mailmap[from]; !ok {
+			r.Mailmap[from] = to
+		}
+	}
+}
+
 func readMailmapFromSource(mm mailmap, src io.Reader) error {
 	// See git C implementation of parse_name_and_email for reference
 	// https://github.com/git/git/blob/master/mailmap.c
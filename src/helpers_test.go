@@ -140,3 +140,53 @@ func TestParseMailmapLine(t *testing.T) {
 		}
 	}
 }
+
+func TestRepoMailmap(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@gmail.com", map[string]string{
+		"a.go":     "1\n2\n",
+		".mailmap": "Abraham Lincoln <abe@git-reviewer.com> <abe@gmail.com>\n",
+	})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+
+	cases := []struct {
+		Backend  Backend
+		Weight   Weight
+		Ignore   bool
+		Expected map[string]int64
+	}{
+		{ShellBackend{}, WeightBlame, false, map[string]int64{"abe@git-reviewer.com": 3, "george@git-reviewer.com": 1}},
+		{ShellBackend{}, WeightCommits, false, map[string]int64{"abe@git-reviewer.com": 2, "george@git-reviewer.com": 1}},
+		{GoGitBackend{}, WeightBlame, false, map[string]int64{"abe@git-reviewer.com": 3, "george@git-reviewer.com": 1}},
+		{GoGitBackend{}, WeightCommits, false, map[string]int64{"abe@git-reviewer.com": 2, "george@git-reviewer.com": 1}},
+		{ShellBackend{}, WeightCommits, true, map[string]int64{
+			"abe@gmail.com": 1, "abe@git-reviewer.com": 1, "george@git-reviewer.com": 1,
+		}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Backend = c.Backend
+		r.Weight = c.Weight
+		r.IgnoreMailmap = c.Ignore
+
+		stats, err := r.FindReviewerStats([]string{"a.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Errorf("Got %v with %T, weight %d and ignore %v, expected %v\n",
+				stats, c.Backend, c.Weight, c.Ignore, c.Expected)
+		}
+		for _, stat := range stats {
+			if expected, ok := c.Expected[stat.Reviewer]; !ok || stat.Count != expected {
+				t.Errorf("Got %d for %s with %T, weight %d and ignore %v, expected %d\n",
+					stat.Count, stat.Reviewer, c.Backend, c.Weight, c.Ignore, expected)
+			}
+		}
+	}
+}
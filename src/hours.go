@@ -0,0 +1,261 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// CommitHabit describes when a collaborator usually commits: their most
+// common UTC offset, in seconds east of UTC, and the most common hour of the
+// day (0 to 23) in their own timezone.
+type CommitHabit struct {
+	Offset int
+	Hour   int
+}
+
+// workingHoursSpan is how many hours either side of their usual commit hour a
+// collaborator is assumed to be working.
+const workingHoursSpan = 4
+
+// workingHoursBoost is the factor by which a reviewer's experience is scaled
+// up for likely being at work.
+const workingHoursBoost = 0.25
+
+// CommitHabits reads the author dates in the history of the base back to
+// Since to find out when each collaborator usually works. Commit dates would
+// give them the clock of whoever rebased or cherry-picked their work.
+func (r *ContributionCounter) CommitHabits() (map[string]CommitHabit, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	commits, err := r.datedCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make(map[string]map[int]int)
+	hours := make(map[string]map[int]int)
+	for _, c := range commits {
+		author := reviewerKey(c.email, r.Mailmap)
+		if offsets[author] == nil {
+			offsets[author] = make(map[int]int)
+			hours[author] = make(map[int]int)
+		}
+		_, offset := c.authored.Zone()
+		offsets[author][offset]++
+		hours[author][c.authored.Hour()]++
+	}
+
+	habits := make(map[string]CommitHabit, len(offsets))
+	for author := range offsets {
+		habits[author] = CommitHabit{Offset: mode(offsets[author]), Hour: mode(hours[author])}
+	}
+
+	return habits, nil
+}
+
+// WorkingHours is the part of the day commits count as made at work, from
+// the Start hour (0 to 23) up to but not including the End hour. A Start
+// after the End is a shift through midnight. The hours are in Location, or
+// in each commit's own timezone when it is nil. The zero value is 9:00 to
+// 18:00 wherever the commit was made.
+type WorkingHours struct {
+	Start    int
+	End      int
+	Location *time.Location
+}
+
+// hours returns the Start and End hours, with 9 and 18 for the zero value.
+func (w WorkingHours) hours() (int, int) {
+	if w.Start == 0 && w.End == 0 {
+		return 9, 18
+	}
+
+	return w.Start, w.End
+}
+
+// String shows the hours as "9:00-18:00", followed by the timezone's name
+// when there is one.
+func (w WorkingHours) String() string {
+	start, end := w.hours()
+	s := fmt.Sprintf("%d:00-%d:00", start, end)
+	if w.Location != nil {
+		s += " " + w.Location.String()
+	}
+
+	return s
+}
+
+// contains reports whether a time falls within the working hours.
+func (w WorkingHours) contains(t time.Time) bool {
+	if w.Location != nil {
+		t = t.In(w.Location)
+	}
+
+	start, end := w.hours()
+	h := t.Hour()
+	if start <= end {
+		return h >= start && h < end
+	}
+
+	return h >= start || h < end
+}
+
+// offHoursCommits finds the commits in the history of the base back to Since
+// whose commit dates are outside WorkingHours.
+func (r *ContributionCounter) offHoursCommits() (map[string]bool, error) {
+	commits, err := r.datedCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	offHours := make(map[string]bool)
+	for _, c := range commits {
+		if !r.WorkingHours.contains(c.committed) {
+			offHours[c.sha] = true
+		}
+	}
+
+	return offHours, nil
+}
+
+// datedCommit is who wrote a commit, when, and when it was committed, each
+// in the timezone it was made in.
+type datedCommit struct {
+	sha       string
+	email     string
+	authored  time.Time
+	committed time.Time
+}
+
+// datedCommits lists the non-merge commits in the history of the base back to
+// Since, newest first.
+func (r *ContributionCounter) datedCommits() ([]datedCommit, error) {
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []datedCommit
+	if !r.runsGit() {
+		err := r.walkHistory(base.Hash, r.since(), func(c *object.Commit) error {
+			if c.NumParents() <= 1 {
+				commits = append(commits, datedCommit{
+					sha:       c.Hash.String(),
+					email:     c.Author.Email,
+					authored:  c.Author.When,
+					committed: c.Committer.When,
+				})
+			}
+			return nil
+		})
+		return commits, err
+	}
+
+	out, err := r.git("log", "--no-merges", "--format=%H%x1f%ae%x1f%aI%x1f%cI",
+		"--since", r.gitDate(r.since()), base.Hash.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git log command")
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+
+		c := datedCommit{sha: fields[0], email: fields[1]}
+		if c.authored, err = time.Parse(time.RFC3339, fields[2]); err != nil {
+			return nil, errors.Wrap(err, "unable to parse author date")
+		}
+		if c.committed, err = time.Parse(time.RFC3339, fields[3]); err != nil {
+			return nil, errors.Wrap(err, "unable to parse commit date")
+		}
+		commits = append(commits, c)
+	}
+
+	return commits, nil
+}
+
+// mode finds the most frequent value, preferring the smallest on ties.
+func mode(counts map[int]int) int {
+	var values []int
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+
+	best := values[0]
+	for _, v := range values {
+		if counts[v] > counts[best] {
+			best = v
+		}
+	}
+
+	return best
+}
+
+// awakeBoost is the factor a reviewer's score is scaled by for likely working
+// at Now: 1 + workingHoursBoost within workingHoursSpan hours of their usual
+// commit hour in their usual timezone, and 1 otherwise or when their habits
+// aren't known.
+func (r *ContributionCounter) awakeBoost(email string) float64 {
+	habit, ok := r.habits[email]
+	if !r.PreferWorkingHours || !ok {
+		return 1
+	}
+
+	now := r.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// Distance around the clock, so 23:00 and 01:00 are two hours apart
+	local := now.In(time.FixedZone("", habit.Offset)).Hour()
+	diff := local - habit.Hour
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > 12 {
+		diff = 24 - diff
+	}
+
+	if diff <= workingHoursSpan {
+		return 1 + workingHoursBoost
+	}
+	return 1
+}
+
+// preferAwake chooses the top n Stats after boosting reviewers likely to be
+// working, leaving the Stats themselves unchanged like preferFast does.
+func (r *ContributionCounter) preferAwake(n int, s Stats) Stats {
+	boosted := make(map[*Stat]float64, len(s))
+	for _, stat := range s {
+		boosted[stat] = stat.Percentage * r.awakeBoost(stat.Reviewer)
+	}
+
+	top := make(Stats, len(s))
+	copy(top, s)
+	sort.SliceStable(top, func(i, j int) bool {
+		return boosted[top[i]] > boosted[top[j]]
+	})
+
+	if len(top) > n {
+		top = top[:n]
+	}
+
+	return top
+}
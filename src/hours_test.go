@@ -0,0 +1,152 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreferWorkingHours(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	newYork := time.FixedZone("EST", -5*60*60)
+	day := time.Now().AddDate(0, 0, -7)
+	at := func(zone *time.Location, hour int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, zone)
+	}
+
+	// Both usually commit mid-morning, george slightly more
+	f.commitAt("abe@git-reviewer.com", at(tokyo, 10), map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commitAt("abe@git-reviewer.com", at(tokyo, 11), map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n7\n8\n9\n"})
+	f.commitAt("george@git-reviewer.com", at(newYork, 10), map[string]string{
+		"a.go": "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n",
+	})
+
+	r := f.counter()
+	habits, err := r.CommitHabits()
+	if err != nil {
+		t.Fatalf("Unexpected error reading habits: %v\n", err)
+	}
+	if h := habits["abe@git-reviewer.com"]; h.Offset != 9*60*60 || h.Hour != 10 {
+		t.Errorf("Got habits %+v for abe, expected 10:00 at UTC+9\n", h)
+	}
+	if h := habits["george@git-reviewer.com"]; h.Offset != -5*60*60 || h.Hour != 10 {
+		t.Errorf("Got habits %+v for george, expected 10:00 at UTC-5\n", h)
+	}
+
+	cases := []struct {
+		Now      time.Time
+		Expected string
+	}{
+		// Morning in Tokyo, night in New York
+		{time.Date(2017, 6, 1, 2, 0, 0, 0, time.UTC), "abe@git-reviewer.com"},
+		// Morning in New York, night in Tokyo
+		{time.Date(2017, 6, 1, 15, 0, 0, 0, time.UTC), "george@git-reviewer.com"},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.PreferWorkingHours = true
+		r.Now = c.Now
+
+		stats, err := r.FindReviewerStats([]string{"a.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+		if stats[0].Reviewer != c.Expected {
+			t.Errorf("Got %s first at %v, expected %s\n", stats[0].Reviewer, c.Now, c.Expected)
+		}
+	}
+}
+
+func TestCommitHabitsUseAuthorDates(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	newYork := time.FixedZone("EST", -5*60*60)
+	day := time.Now().AddDate(0, 0, -7)
+
+	// abe's work, committed hours later in New York by whoever rebased it
+	f.write(map[string]string{"a.go": "1\n"})
+	f.git("add", "-A")
+	f.gitEnv([]string{
+		"GIT_AUTHOR_NAME=abe",
+		"GIT_AUTHOR_EMAIL=abe@git-reviewer.com",
+		"GIT_AUTHOR_DATE=" + time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, tokyo).Format(time.RFC3339),
+		"GIT_COMMITTER_DATE=" + time.Date(day.Year(), day.Month(), day.Day(), 22, 0, 0, 0, newYork).Format(time.RFC3339),
+	}, "commit", "-q", "-m", "Rebased change by abe")
+
+	habits, err := f.counter().CommitHabits()
+	if err != nil {
+		t.Fatalf("Unexpected error reading habits: %v\n", err)
+	}
+	if h := habits["abe@git-reviewer.com"]; h.Offset != 9*60*60 || h.Hour != 10 {
+		t.Errorf("Got habits %+v for abe, expected 10:00 at UTC+9\n", h)
+	}
+}
+
+func TestExcludeOffHours(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	day := time.Now().AddDate(0, 0, -7)
+	at := func(zone *time.Location, hour int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, 30, 0, 0, zone)
+	}
+
+	f.commitAt("abe@git-reviewer.com", at(time.UTC, 10), map[string]string{"a.go": "1\n2\n3\n"})
+	// A hotfix in the middle of the night
+	f.commitAt("tom@git-reviewer.com", at(time.UTC, 23), map[string]string{"a.go": "1\n2\n3\n4\n5\n"})
+	// The middle of the night in Tokyo, but the middle of the day in UTC
+	f.commitAt("george@git-reviewer.com", at(tokyo, 1), map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n"})
+
+	cases := []struct {
+		Weight   Weight
+		Hours    WorkingHours
+		Exclude  bool
+		Expected map[string]int64
+	}{
+		{WeightBlame, WorkingHours{}, false, map[string]int64{
+			"abe@git-reviewer.com": 3, "tom@git-reviewer.com": 2, "george@git-reviewer.com": 1,
+		}},
+		{WeightBlame, WorkingHours{}, true, map[string]int64{"abe@git-reviewer.com": 3}},
+		{WeightCommits, WorkingHours{}, true, map[string]int64{"abe@git-reviewer.com": 1}},
+		{WeightBlame, WorkingHours{Location: time.UTC}, true, map[string]int64{
+			"abe@git-reviewer.com": 3, "george@git-reviewer.com": 1,
+		}},
+		// A night shift
+		{WeightBlame, WorkingHours{Start: 22, End: 6, Location: time.UTC}, true, map[string]int64{
+			"tom@git-reviewer.com": 2,
+		}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Weight = c.Weight
+		r.ExcludeOffHours = c.Exclude
+		r.WorkingHours = c.Hours
+
+		stats, err := r.FindReviewerStats([]string{"a.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Errorf("Got %v with hours %s and exclude %v, expected %v\n", stats, c.Hours, c.Exclude, c.Expected)
+		}
+		for _, stat := range stats {
+			if expected, ok := c.Expected[stat.Reviewer]; !ok || stat.Count != expected {
+				t.Errorf("Got %d for %s with hours %s and exclude %v, expected %d\n",
+					stat.Count, stat.Reviewer, c.Hours, c.Exclude, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// identity is a name and email pair someone has committed under.
+type identity struct {
+	name  string
+	email string
+}
+
+func (id identity) String() string {
+	return fmt.Sprintf("%s <%s>", id.name, id.email)
+}
+
+// DuplicateIdentityWarnings looks through the history of the paths for people
+// who appear to commit under more than one identity: similar names with
+// different emails, or the same email user with different names. It doesn't
+// merge anything, but the warnings point at entries worth adding to a
+// mailmap. Identities the mailmap already unifies are not reported.
+func (r *ContributionCounter) DuplicateIdentityWarnings(paths []string) ([]string, error) {
+	defer r.startRun()()
+
+	records, err := r.ContributionRecords(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[identity]bool)
+	byName := make(map[string][]identity)
+	byUser := make(map[string][]identity)
+
+	for _, rec := range records {
+		id := identity{reviewerKey(rec.Author, r.Mailmap), rec.Email}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		name := normalizeName(id.name)
+		byName[name] = append(byName[name], id)
+
+		user := strings.ToLower(strings.SplitN(id.email, "@", 2)[0])
+		byUser[user] = append(byUser[user], id)
+	}
+
+	var warnings []string
+	warn := func(groups map[string][]identity, differ func(a, b identity) bool, why string) {
+		for _, ids := range groups {
+			sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+			for i := 0; i < len(ids); i++ {
+				for j := i + 1; j < len(ids); j++ {
+					if differ(ids[i], ids[j]) {
+						warnings = append(warnings, fmt.Sprintf(
+							"Possible duplicate identity: '%s' and '%s' have %s",
+							ids[i], ids[j], why))
+					}
+				}
+			}
+		}
+	}
+
+	warn(byName, func(a, b identity) bool {
+		return !strings.EqualFold(a.email, b.email)
+	}, "similar names but different emails")
+	warn(byUser, func(a, b identity) bool {
+		return normalizeName(a.name) != normalizeName(b.name)
+	}, "the same email user but different names")
+
+	sort.Strings(warnings)
+	return warnings, nil
+}
+
+// normalizeName reduces a name to lowercase letters and digits so that
+// "Jane Doe", "jane doe", and "Jane  Doe." compare equal.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
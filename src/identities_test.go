@@ -0,0 +1,52 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDuplicateIdentityWarnings(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commitAs("Jane Doe", "jane@corp.com", map[string]string{"a.go": "1\n"})
+	f.commitAs("jane doe", "jane.doe@gmail.com", map[string]string{"a.go": "1\n2\n"})
+	f.commitAs("Abe Lincoln", "abe@corp.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commitAs("Abraham", "abe@gmail.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commitAs("George", "george@corp.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n"})
+
+	warnings, err := f.counter().DuplicateIdentityWarnings([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding duplicates: %v\n", err)
+	}
+
+	if l := len(warnings); l != 2 {
+		t.Fatalf("Got %d warnings, expected 2: %v\n", l, warnings)
+	}
+
+	joined := strings.Join(warnings, "\n")
+	for _, expected := range []string{
+		"'Jane Doe <jane@corp.com>' and 'jane doe <jane.doe@gmail.com>' have similar names",
+		"'Abe Lincoln <abe@corp.com>' and 'Abraham <abe@gmail.com>' have the same email user",
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("Expected a warning containing \"%s\", got:\n%s", expected, joined)
+		}
+	}
+	if strings.Contains(joined, "George") {
+		t.Errorf("Didn't expect a warning about George, got:\n%s", joined)
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	for _, name := range []string{"Jane Doe", "jane doe", "Jane  Doe."} {
+		if n := normalizeName(name); n != "janedoe" {
+			t.Errorf("Normalized '%s' to '%s', expected 'janedoe'\n", name, n)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// importCommits finds the commits without parents in the history of the
+// base. There is usually just one, but histories joined from several
+// repositories have one for each.
+func (r *ContributionCounter) importCommits() (map[string]bool, error) {
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make(map[string]bool)
+	if !r.runsGit() {
+		err := r.walkHistory(base.Hash, "", func(c *object.Commit) error {
+			if c.NumParents() == 0 {
+				roots[c.Hash.String()] = true
+			}
+			return nil
+		})
+		return roots, err
+	}
+
+	out, err := r.git("rev-list", "--max-parents=0", base.Hash.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git rev-list command")
+	}
+
+	for _, sha := range strings.Fields(string(out)) {
+		roots[sha] = true
+	}
+
+	return roots, nil
+}
@@ -0,0 +1,63 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+)
+
+func TestIgnoreImportCommit(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// One person brings over the whole project, then others work on it
+	f.commit("importer@git-reviewer.com", map[string]string{
+		"a.go": "1\n2\n3\n4\n",
+		"b.go": "5\n6\n7\n8\n",
+		"c.go": "9\n10\n",
+	})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\nx\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"b.go": "y\n6\n7\n8\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "z\n2\n3\n4\nx\n"})
+
+	paths := []string{"a.go", "b.go", "c.go"}
+	cases := []struct {
+		Weight   Weight
+		Ignore   bool
+		Expected map[string]int64
+	}{
+		{WeightBlame, false, map[string]int64{
+			"importer@git-reviewer.com": 8, "abe@git-reviewer.com": 2, "george@git-reviewer.com": 1,
+		}},
+		{WeightBlame, true, map[string]int64{"abe@git-reviewer.com": 2, "george@git-reviewer.com": 1}},
+		{WeightCommits, false, map[string]int64{
+			"importer@git-reviewer.com": 3, "abe@git-reviewer.com": 2, "george@git-reviewer.com": 1,
+		}},
+		{WeightCommits, true, map[string]int64{"abe@git-reviewer.com": 2, "george@git-reviewer.com": 1}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Weight = c.Weight
+		r.IgnoreImportCommit = c.Ignore
+
+		stats, err := r.FindReviewerStats(paths)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Errorf("Got %d reviewers with weight %d and ignore %v, expected %d\n",
+				len(stats), c.Weight, c.Ignore, len(c.Expected))
+		}
+		for _, stat := range stats {
+			if expected := c.Expected[stat.Reviewer]; stat.Count != expected {
+				t.Errorf("Got %d for %s with weight %d and ignore %v, expected %d\n",
+					stat.Count, stat.Reviewer, c.Weight, c.Ignore, expected)
+			}
+		}
+	}
+}
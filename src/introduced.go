@@ -0,0 +1,169 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// FindIntroducedFiles lists the files created on the branch that only the
+// person running the analysis has worked on. Nobody else has history with
+// them, so FindFiles leaves them out of the reviewer computation; this lets
+// callers still show them alongside the suggestions. The branch author is
+// identified as for ExcludeSelf.
+func (r *ContributionCounter) FindIntroducedFiles() ([]string, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	self, err := r.selfIdentity()
+	if err != nil {
+		return nil, err
+	}
+	self = reviewerKey(self, r.Mailmap)
+
+	created, err := r.createdOnBranch(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for _, p := range created {
+		if considerExt(p, r) && considerPath(p, r) {
+			added = append(added, p)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	shared, err := r.touchedByOthers(base, self, added)
+	if err != nil {
+		return nil, err
+	}
+
+	var introduced []string
+	for _, p := range added {
+		if !shared[p] {
+			introduced = append(introduced, p)
+		}
+	}
+	sort.Strings(introduced)
+
+	return introduced, nil
+}
+
+// createdOnBranch lists the files HEAD has that the base doesn't.
+func (r *ContributionCounter) createdOnBranch(base *object.Commit) ([]string, error) {
+	if !r.runsGit() {
+		head, err := r.headCommit()
+		if err != nil {
+			return nil, err
+		}
+
+		from, err := base.Tree()
+		if err != nil {
+			return nil, err
+		}
+		to, err := head.Tree()
+		if err != nil {
+			return nil, err
+		}
+
+		return changedPaths(from, to, true)
+	}
+
+	out, err := r.git("diff", "--name-only", "--diff-filter=A", base.Hash.String(), "HEAD")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git diff command")
+	}
+
+	var created []string
+	for _, p := range strings.Split(string(out), "\n") {
+		if len(p) > 0 {
+			created = append(created, p)
+		}
+	}
+
+	return created, nil
+}
+
+// touchedByOthers finds which of paths anyone but self changed in the
+// commits on the branch.
+func (r *ContributionCounter) touchedByOthers(base *object.Commit, self string, paths []string) (map[string]bool, error) {
+	shared := make(map[string]bool)
+	if !r.runsGit() {
+		head, err := r.headCommit()
+		if err != nil {
+			return nil, err
+		}
+
+		inBase := make(map[plumbing.Hash]bool)
+		err = r.walkHistory(base.Hash, "", func(c *object.Commit) error {
+			inBase[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.walkHistory(head.Hash, "", func(c *object.Commit) error {
+			if inBase[c.Hash] || c.NumParents() > 1 {
+				return nil
+			}
+			if reviewerKey(c.Author.Email, r.Mailmap) == self {
+				return nil
+			}
+
+			changed, err := commitPaths(c, false)
+			if err != nil {
+				return err
+			}
+			for _, p := range changed {
+				if underAny(p, paths) {
+					shared[p] = true
+				}
+			}
+			return nil
+		})
+		return shared, err
+	}
+
+	args := append([]string{
+		"log", "--no-renames", "--name-only", "--format=%x00%ae",
+		base.Hash.String() + "..HEAD", "--",
+	}, paths...)
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git log command")
+	}
+
+	// Every commit starts with a NUL-prefixed author line, followed by the
+	// names of the files it touched.
+	var author string
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			author = reviewerKey(line[1:], r.Mailmap)
+		case len(line) > 0 && author != self:
+			shared[line] = true
+		}
+	}
+
+	return shared, nil
+}
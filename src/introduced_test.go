@@ -0,0 +1,46 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindIntroducedFiles(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	f.commit("me@git-reviewer.com", map[string]string{"new.go": "1\n", "new file.go": "1\n", "mine.go": "1\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"shared.go": "1\n"})
+	f.commit("me@git-reviewer.com", map[string]string{"shared.go": "1\n2\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"mine.go": "1\n2\n"})
+
+	r := f.counter()
+	introduced, err := r.FindIntroducedFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding introduced files: %v\n", err)
+	}
+
+	// mine.go and shared.go are new, but george has worked on them too
+	if expected := []string{"new file.go", "new.go"}; !reflect.DeepEqual(introduced, expected) {
+		t.Errorf("Got introduced files %v, expected %v\n", introduced, expected)
+	}
+
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	for _, file := range files {
+		switch file {
+		case "new.go", "new file.go", "mine.go", "shared.go":
+			t.Errorf("Branch-new file %s should not be used to find reviewers\n", file)
+		}
+	}
+	if len(files) != 3 {
+		t.Errorf("Got files %v, expected the 3 that existed on master\n", files)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONSchemaVersion identifies the shape of the documents JSONFormatter
+// writes, so downstream consumers can adapt when it changes. It is bumped
+// whenever a field is added, removed, or changes meaning.
+//
+// Version 1 has schemaVersion, confidence, and a reviewers list whose entries
+// have reviewer, percentage (0 to 1), count, and lastCommit ("YYYY-MM-DD").
+//
+// Version 2 adds files to reviewer entries: how many changed files their
+// count spans.
+//
+// Version 3 adds reasons to reviewer entries: the codes for why they were
+// suggested (see ReasonCodeOwner and the other Reason constants), which may
+// be empty.
+const JSONSchemaVersion = 3
+
+// jsonReport is the document JSONFormatter writes.
+type jsonReport struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	Confidence    float64      `json:"confidence"`
+	Reviewers     []jsonReview `json:"reviewers"`
+}
+
+type jsonReview struct {
+	Reviewer   string   `json:"reviewer"`
+	Percentage float64  `json:"percentage"`
+	Count      int64    `json:"count"`
+	Files      int      `json:"files"`
+	LastCommit string   `json:"lastCommit"`
+	Reasons    []string `json:"reasons"`
+}
+
+// JSONFormatter writes a Report as a JSON document for other tools to consume.
+// See JSONSchemaVersion for the document's fields.
+type JSONFormatter struct {
+	// Indent pretty-prints the document.
+	Indent bool
+}
+
+// Write encodes the report to w.
+func (f JSONFormatter) Write(w io.Writer, report *Report) error {
+	doc := jsonReport{
+		SchemaVersion: JSONSchemaVersion,
+		Confidence:    report.Confidence,
+		Reviewers:     make([]jsonReview, len(report.Reviewers)),
+	}
+
+	for i, stat := range report.Reviewers {
+		doc.Reviewers[i] = jsonReview{
+			Reviewer:   stat.Reviewer,
+			Percentage: stat.Percentage,
+			Count:      stat.Count,
+			Files:      stat.Files,
+			LastCommit: stat.LastCommit,
+			Reasons:    nonNil(stat.Reasons),
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	if f.Indent {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(doc)
+}
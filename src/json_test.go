@@ -0,0 +1,53 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONFormatterSchemaVersion(t *testing.T) {
+	report := &Report{
+		Reviewers: Stats{
+			&Stat{Reviewer: "abe@git-reviewer.com", Percentage: 0.5, Count: 2, LastCommit: "2017-06-01"},
+		},
+		Confidence: 0.25,
+	}
+
+	for _, indent := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := (JSONFormatter{Indent: indent}).Write(&buf, report); err != nil {
+			t.Fatalf("Unexpected error writing JSON: %v\n", err)
+		}
+
+		var doc map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("Unable to parse JSON output: %v\n%s", err, buf.String())
+		}
+
+		if v, ok := doc["schemaVersion"].(float64); !ok || int(v) != JSONSchemaVersion {
+			t.Errorf("Got schemaVersion %v, expected %d\n", doc["schemaVersion"], JSONSchemaVersion)
+		}
+		if JSONSchemaVersion != 3 {
+			t.Errorf("Schema version changed to %d; update the documented fields and this test\n",
+				JSONSchemaVersion)
+		}
+
+		reviewers, ok := doc["reviewers"].([]interface{})
+		if !ok || len(reviewers) != 1 {
+			t.Fatalf("Got reviewers %v, expected one entry\n", doc["reviewers"])
+		}
+
+		entry := reviewers[0].(map[string]interface{})
+		for _, field := range []string{"reviewer", "percentage", "count", "files", "lastCommit", "reasons"} {
+			if _, ok := entry[field]; !ok {
+				t.Errorf("Reviewer entry is missing '%s': %v\n", field, entry)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bufio"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// languageExtensions maps file extensions to the language they're written in.
+// Headers ending in ".h" could be C or C++, so they're looked at separately.
+var languageExtensions = map[string]string{
+	".go":    "Go",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".py":    "Python",
+	".rb":    "Ruby",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".rs":    "Rust",
+	".c":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".cxx":   "C++",
+	".hh":    "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".swift": "Swift",
+	".php":   "PHP",
+	".sh":    "Shell",
+	".bash":  "Shell",
+}
+
+// languageInterpreters maps the interpreters named in shebang lines, without
+// any version number, to the language of the scripts they run.
+var languageInterpreters = map[string]string{
+	"python":  "Python",
+	"ruby":    "Ruby",
+	"node":    "JavaScript",
+	"ts-node": "TypeScript",
+	"deno":    "TypeScript",
+	"php":     "PHP",
+	"sh":      "Shell",
+	"bash":    "Shell",
+	"zsh":     "Shell",
+}
+
+// cppHeaderRx finds constructs in a header that only C++ has.
+var cppHeaderRx = regexp.MustCompile(`(?m)^\s*(class|namespace|template)\b|\b(public|private|protected):|std::`)
+
+// detectLanguage names the language a file is written in from its extension,
+// or from its shebang line when it has no extension, or "" when it can't
+// tell. A ".h" header counts as C++ when it uses C++ constructs, and as C
+// otherwise. The contents are only read when the name isn't enough.
+func detectLanguage(name string, contents func() (string, error)) string {
+	ext := path.Ext(name)
+	if lang, ok := languageExtensions[ext]; ok {
+		return lang
+	}
+	if ext != ".h" && ext != "" {
+		return ""
+	}
+
+	text, err := contents()
+	if err != nil {
+		return ""
+	}
+
+	if ext == ".h" {
+		if cppHeaderRx.MatchString(text) {
+			return "C++"
+		}
+		return "C"
+	}
+
+	return shebangLanguage(text)
+}
+
+// shebangLanguage reads the interpreter from a "#!" first line, looking past
+// env, and names the language it runs.
+func shebangLanguage(text string) string {
+	first, _ := bufio.NewReader(strings.NewReader(text)).ReadString('\n')
+	if !strings.HasPrefix(first, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(first[2:])
+	if len(fields) > 0 && path.Base(fields[0]) == "env" {
+		fields = fields[1:]
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+			fields = fields[1:]
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := strings.TrimRight(path.Base(fields[0]), "0123456789.")
+	return languageInterpreters[interpreter]
+}
+
+// considerLanguage reports whether a file is in one of OnlyLanguages, or
+// whether there are no OnlyLanguages to limit by. Language names are matched
+// ignoring case.
+func considerLanguage(name string, contents func() (string, error), opts *ContributionCounter) bool {
+	if len(opts.OnlyLanguages) == 0 {
+		return true
+	}
+
+	lang := detectLanguage(name, contents)
+	for _, only := range opts.OnlyLanguages {
+		if len(lang) > 0 && strings.EqualFold(lang, only) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOnlyLanguages(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"main.go":  "package main\n",
+		"app.ts":   "let x = 1\n",
+		"add.h":    "int add(int a, int b);\n",
+		"shape.h":  "class Shape {\npublic:\n  virtual double area() const;\n};\n",
+		"bin/tool": "#!/usr/bin/env python3\nprint('hi')\n",
+		"bin/run":  "#!/bin/sh\necho hi\n",
+	})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"main.go":  "package main\n\n",
+		"app.ts":   "let x = 2\n",
+		"add.h":    "int add(int a, int b);\n\n",
+		"shape.h":  "class Shape {\npublic:\n  virtual double area() const;\n};\n\n",
+		"bin/tool": "#!/usr/bin/env python3\nprint('hello')\n",
+		"bin/run":  "#!/bin/sh\necho hello\n",
+	})
+
+	cases := []struct {
+		Languages []string
+		Expected  []string
+	}{
+		{nil, []string{"add.h", "app.ts", "bin/run", "bin/tool", "main.go", "shape.h"}},
+		{[]string{"Go", "TypeScript"}, []string{"app.ts", "main.go"}},
+		// Headers share an extension, so it comes down to what's in them
+		{[]string{"C"}, []string{"add.h"}},
+		{[]string{"c++"}, []string{"shape.h"}},
+		{[]string{"Python", "Shell"}, []string{"bin/run", "bin/tool"}},
+		{[]string{"Haskell"}, nil},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.OnlyLanguages = c.Languages
+
+		files, err := r.FindFiles()
+		if err != nil {
+			t.Fatalf("Unexpected error finding files: %v\n", err)
+		}
+
+		sort.Strings(files)
+		if !reflect.DeepEqual(files, c.Expected) {
+			t.Errorf("Got files %v for %v, expected %v\n", files, c.Languages, c.Expected)
+		}
+	}
+}
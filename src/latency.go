@@ -0,0 +1,71 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"sort"
+	"time"
+)
+
+// LatencyProvider reports how long a reviewer historically takes to respond
+// to a review request, if known. Sources could be commit trailers, a code
+// review system's API, or a static map maintained by the team.
+type LatencyProvider func(email string) (time.Duration, bool)
+
+// maxLatencyBoost is the largest factor by which a reviewer's experience is
+// scaled up for responding quickly.
+const maxLatencyBoost = 0.25
+
+// preferFast chooses the top n Stats after boosting reviewers with a history
+// of responding quickly. A reviewer who responds instantly has their share of
+// owned lines scaled up by maxLatencyBoost, and the boost halves for every day
+// of typical turnaround:
+//
+//	score = Percentage * (1 + maxLatencyBoost / (1 + latency in days))
+//
+// Reviewers with unknown latency are not boosted. Equal scores are broken in
+// favor of the faster reviewer. The Stats themselves are left unchanged so
+// reported experience stays accurate. With PreferWorkingHours, the scores are
+// boosted for that as well; see awakeBoost.
+func (r *ContributionCounter) preferFast(n int, s Stats) Stats {
+	type ranked struct {
+		stat    *Stat
+		score   float64
+		latency time.Duration
+		known   bool
+	}
+
+	all := make([]ranked, len(s))
+	for i, stat := range s {
+		all[i] = ranked{stat: stat, score: stat.Percentage * r.awakeBoost(stat.Reviewer)}
+		if latency, ok := r.LatencyProvider(stat.Reviewer); ok {
+			days := latency.Hours() / 24
+			all[i].score *= 1 + maxLatencyBoost/(1+days)
+			all[i].latency, all[i].known = latency, true
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		if all[i].known != all[j].known {
+			return all[i].known
+		}
+		return all[i].latency < all[j].latency
+	})
+
+	if len(all) < n {
+		n = len(all)
+	}
+
+	top := make(Stats, n)
+	for i := range top {
+		top[i] = all[i].stat
+	}
+
+	return top
+}
@@ -0,0 +1,62 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+	"time"
+)
+
+func stubLatency(latencies map[string]time.Duration) LatencyProvider {
+	return func(email string) (time.Duration, bool) {
+		l, ok := latencies[email]
+		return l, ok
+	}
+}
+
+func TestPreferFastOrdersByTurnaround(t *testing.T) {
+	// Both reviewers get a similar boost, but the faster one edges ahead.
+	r := &ContributionCounter{LatencyProvider: stubLatency(map[string]time.Duration{
+		"slow@git-reviewer.com": 48 * time.Hour,
+		"fast@git-reviewer.com": 47 * time.Hour,
+	})}
+
+	stats := Stats{
+		&Stat{Reviewer: "slow@git-reviewer.com", Percentage: 0.5},
+		&Stat{Reviewer: "fast@git-reviewer.com", Percentage: 0.5},
+	}
+
+	top := r.preferFast(2, stats)
+	if top[0].Reviewer != "fast@git-reviewer.com" {
+		t.Errorf("Expected faster reviewer first, got %s\n", top[0].Reviewer)
+	}
+}
+
+func TestPreferFastBoostsQuickReviewers(t *testing.T) {
+	r := &ContributionCounter{LatencyProvider: stubLatency(map[string]time.Duration{
+		"quick@git-reviewer.com": 0,
+	})}
+
+	stats := Stats{
+		&Stat{Reviewer: "unknown@git-reviewer.com", Percentage: 0.5},
+		&Stat{Reviewer: "quick@git-reviewer.com", Percentage: 0.45},
+		&Stat{Reviewer: "other@git-reviewer.com", Percentage: 0.05},
+	}
+
+	top := r.preferFast(2, stats)
+	if l := len(top); l != 2 {
+		t.Fatalf("Got %d reviewers, expected 2\n", l)
+	}
+	if top[0].Reviewer != "quick@git-reviewer.com" {
+		t.Errorf("Expected quick reviewer to be boosted first, got %s\n", top[0].Reviewer)
+	}
+	if top[0].Percentage != 0.45 {
+		t.Errorf("Expected reported experience to be unchanged, got %f\n", top[0].Percentage)
+	}
+	if top[1].Reviewer != "unknown@git-reviewer.com" {
+		t.Errorf("Expected unknown latency reviewer second, got %s\n", top[1].Reviewer)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MarkdownFormatter writes a Report as a Markdown table, for pasting into
+// pull request descriptions or keeping alongside other notes.
+type MarkdownFormatter struct{}
+
+// markdownEscaper keeps reviewer names from breaking out of their table
+// cells.
+var markdownEscaper = strings.NewReplacer("|", "\\|", "\n", " ")
+
+// Write emits a table with one row per reviewer to w, with shares at the
+// ScorePrecision of the counter that suggested them, followed by the
+// report's confidence.
+func (f MarkdownFormatter) Write(w io.Writer, report *Report) error {
+	if _, err := fmt.Fprint(w, "| Rank | Reviewer | Count | Files | Share | Last commit |\n"+
+		"| ---: | --- | ---: | ---: | ---: | --- |\n"); err != nil {
+		return err
+	}
+
+	for _, stat := range report.Reviewers {
+		who := stat.Reviewer
+		if len(stat.Name) > 0 {
+			who = stat.Name + " <" + stat.Reviewer + ">"
+		}
+
+		if _, err := fmt.Fprintf(w, "| %d | %s | %d | %d | %s | %s |\n",
+			stat.Rank, markdownEscaper.Replace(who), stat.Count, stat.Files,
+			formatScore(stat.Percentage, resolvePrecision(stat.precision)), stat.LastCommit); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nConfidence: %.2f\n", report.Confidence)
+	return err
+}
@@ -0,0 +1,116 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics describes the work done by a run: one call to an exported method of
+// ContributionCounter that reads the repository, such as FindReviewers,
+// including whatever else it calls on the counter.
+type Metrics struct {
+	// FilesAnalyzed is how many changed files were scored.
+	FilesAnalyzed int
+	// GitCommands is how many external git commands were run.
+	GitCommands int
+	// CacheHits and CacheMisses count Eligibility answers that were reused
+	// from earlier in the run and that had to be asked for.
+	CacheHits   int
+	CacheMisses int
+	// Duration is how long the run took.
+	Duration time.Duration
+}
+
+// runMetrics accumulates Metrics while a run's goroutines update them. It
+// also remembers the Eligibility answers given during the run, so nobody is
+// asked about twice in one run but answers never carry over to the next, and
+// the defaults resolved for it (see perRun).
+type runMetrics struct {
+	mu       sync.Mutex
+	m        Metrics
+	start    time.Time
+	eligible map[string]bool
+	resolved map[string]string
+	running  bool
+}
+
+// Metrics returns what the most recent run did, or zero values when nothing
+// has run yet.
+func (r *ContributionCounter) Metrics() Metrics {
+	if r == nil || r.metrics == nil {
+		return Metrics{}
+	}
+
+	r.metrics.mu.Lock()
+	defer r.metrics.mu.Unlock()
+
+	return r.metrics.m
+}
+
+// startRun begins a run, with fresh metrics and nothing remembered from
+// earlier runs, and returns what ends it. Exported methods start one as they
+// are called, as in "defer r.startRun()()"; when one is already under way,
+// they are part of it and this does nothing.
+func (r *ContributionCounter) startRun() (stop func()) {
+	if r == nil || (r.metrics != nil && r.metrics.running) {
+		return func() {}
+	}
+
+	r.metrics = &runMetrics{
+		start:    time.Now(),
+		eligible: make(map[string]bool),
+		resolved: make(map[string]string),
+		running:  true,
+	}
+	return r.stopRun
+}
+
+// stopRun records how long the run took and ends it.
+func (r *ContributionCounter) stopRun() {
+	r.count(func(m *Metrics) {
+		m.Duration = time.Since(r.metrics.start)
+		r.metrics.running = false
+	})
+}
+
+// perRun returns what resolve gave the first time key was asked for in the
+// current run, so defaults that take a git command to work out, like the
+// base, are only worked out once however often they are needed. Outside a
+// run, resolve is called every time.
+func (r *ContributionCounter) perRun(key string, resolve func() string) string {
+	run := r.metrics
+	if run == nil || !run.running {
+		return resolve()
+	}
+
+	run.mu.Lock()
+	v, ok := run.resolved[key]
+	run.mu.Unlock()
+	if ok {
+		return v
+	}
+
+	v = resolve()
+	run.mu.Lock()
+	run.resolved[key] = v
+	run.mu.Unlock()
+
+	return v
+}
+
+// count updates the current run's metrics, if a run is being measured.
+func (r *ContributionCounter) count(update func(*Metrics)) {
+	if r.metrics == nil {
+		return
+	}
+
+	r.metrics.mu.Lock()
+	defer r.metrics.mu.Unlock()
+
+	update(&r.metrics.m)
+}
@@ -0,0 +1,52 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	if m := r.Metrics(); m != (Metrics{}) {
+		t.Errorf("Got metrics %+v before any run, expected none\n", m)
+	}
+
+	r.BaseBranch = "master"
+	r.Since = "2000-01-01"
+	r.Eligibility = AllEligible{}
+
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+
+	for run := 0; run < 2; run++ {
+		if _, err := r.FindReviewers(files); err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		m := r.Metrics()
+		if m.FilesAnalyzed != len(files) {
+			t.Errorf("Run %d analyzed %d files, expected %d\n", run, m.FilesAnalyzed, len(files))
+		}
+		// At least one blame per file, on top of checking the repository
+		if m.GitCommands <= len(files) {
+			t.Errorf("Run %d ran %d git commands, expected more than %d\n", run, m.GitCommands, len(files))
+		}
+		// Abe, George and me are each checked once a run
+		if m.CacheHits != 0 || m.CacheMisses != 3 {
+			t.Errorf("Run %d got %d cache hits and %d misses, expected 3 misses\n",
+				run, m.CacheHits, m.CacheMisses)
+		}
+		if m.Duration <= 0 {
+			t.Errorf("Run %d took %v, expected a duration\n", run, m.Duration)
+		}
+	}
+}
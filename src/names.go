@@ -0,0 +1,80 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// nameReviewers fills in the Name of each Stat with the name its reviewer
+// most recently committed to the base under since Since. Reviewers are
+// already told apart by email, so several names for one email end up as one
+// Stat; this just picks which of them to show.
+func (r *ContributionCounter) nameReviewers(top Stats) error {
+	if len(top) == 0 {
+		return nil
+	}
+
+	authors, err := r.recentAuthors()
+	if err != nil {
+		return err
+	}
+
+	// The log is newest first, so the first name seen for an email wins
+	names := make(map[string]string)
+	for _, a := range authors {
+		email := reviewerKey(a.email, r.Mailmap)
+		if _, ok := names[email]; !ok {
+			names[email] = a.name
+		}
+	}
+
+	for _, stat := range top {
+		stat.Name = names[stat.Reviewer]
+	}
+
+	return nil
+}
+
+// author is who wrote a commit, as committed.
+type author struct {
+	email string
+	name  string
+}
+
+// recentAuthors lists the author of every commit to the base since Since,
+// newest first.
+func (r *ContributionCounter) recentAuthors() ([]author, error) {
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	var authors []author
+	if !r.runsGit() {
+		err := r.walkHistory(base.Hash, r.since(), func(c *object.Commit) error {
+			authors = append(authors, author{c.Author.Email, c.Author.Name})
+			return nil
+		})
+		return authors, err
+	}
+
+	out, err := r.git("log", "--format=%ae%x1f%an", "--since", r.gitDate(r.since()), base.Hash.String(), "--")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git log command")
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.SplitN(line, "\x1f", 2); len(fields) == 2 {
+			authors = append(authors, author{fields[0], fields[1]})
+		}
+	}
+
+	return authors, nil
+}
@@ -0,0 +1,211 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FindReviewersForPatch finds the top reviewers for a patch as it would land
+// on base rather than where it was written, as for backports to a release
+// branch. The patch is a unified diff, as from git diff or git
+// format-patch, and the files it changes are taken by their names before
+// the patch; files it creates, or that base doesn't have, have no history
+// there and are left out, like the filters FindFiles applies. The reviewers
+// are formatted as by FindReviewers. An empty base means the usual one (see
+// BaseBranch).
+func (r *ContributionCounter) FindReviewersForPatch(patch io.Reader, base string) (string, error) {
+	if r == nil {
+		return "", ErrNilCounter
+	}
+	defer r.startRun()()
+
+	paths, err := patchFiles(patch)
+	if err != nil {
+		return "", err
+	}
+
+	patched := *r
+	if len(base) > 0 {
+		patched.BaseBranch = base
+	}
+	if err := patched.prepare(); err != nil {
+		return "", err
+	}
+
+	mc, err := patched.baseCommit()
+	if err != nil {
+		return "", err
+	}
+
+	var kept []string
+	for _, p := range paths {
+		if !considerExt(p, &patched) || !considerPath(p, &patched) {
+			continue
+		}
+
+		f, err := mc.File(p)
+		if err != nil {
+			continue
+		}
+		if considerLanguage(p, f.Contents, &patched) {
+			kept = append(kept, p)
+		}
+	}
+
+	return patched.FindReviewers(kept)
+}
+
+// patchFiles lists the files a unified diff changes, by their names before
+// it, in the order it changes them. Files it creates are left out.
+func patchFiles(patch io.Reader) ([]string, error) {
+	var (
+		files []string
+		// The name so far of the file being read about, and whether the
+		// patch creates it
+		name    string
+		created bool
+		inFile  bool
+		// Whether its "--- " line has been read, after which another one
+		// starts the next file in patches without "diff --git" lines
+		minus bool
+		// Lines left in the current hunk on either side
+		oldLeft, newLeft int
+	)
+
+	finish := func() {
+		if inFile && !created && len(name) > 0 {
+			files = append(files, name)
+		}
+		name, created, inFile, minus = "", false, false, false
+	}
+
+	scn := bufio.NewScanner(patch)
+	scn.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scn.Scan() {
+		line := scn.Text()
+
+		// Hunk bodies can hold anything, including lines that look like
+		// headers, so they're skipped by their lengths
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+			case strings.HasPrefix(line, "\\"):
+			default:
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			finish()
+			inFile = true
+			name = gitHeaderPath(strings.TrimPrefix(line, "diff --git "))
+		case strings.HasPrefix(line, "new file mode"):
+			created = true
+		case strings.HasPrefix(line, "rename from "), strings.HasPrefix(line, "copy from "):
+			name = patchPath(line[strings.Index(line, "from ")+len("from "):], "")
+		case strings.HasPrefix(line, "--- "):
+			if !inFile || minus {
+				finish()
+				inFile = true
+			}
+			minus = true
+			if from := strings.TrimPrefix(line, "--- "); strings.HasPrefix(from, "/dev/null") {
+				created = true
+			} else {
+				name = patchPath(from, "a/")
+			}
+		case strings.HasPrefix(line, "@@ "):
+			var err error
+			if oldLeft, newLeft, err = hunkLengths(line); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scn.Err(); err != nil {
+		return nil, errors.Wrap(err, "unable to read patch")
+	}
+	finish()
+
+	return files, nil
+}
+
+// hunkLengths reads how many lines a hunk has before and after the patch
+// from its "@@ -start,length +start,length @@" header. A missing length is
+// one line.
+func hunkLengths(header string) (int, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, errors.Errorf("unexpected hunk header '%s'", header)
+	}
+
+	length := func(field string) (int, error) {
+		parts := strings.SplitN(field[1:], ",", 2)
+		if len(parts) == 1 {
+			return 1, nil
+		}
+		return strconv.Atoi(parts[1])
+	}
+
+	before, err := length(fields[1])
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "unexpected hunk header '%s'", header)
+	}
+	after, err := length(fields[2])
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "unexpected hunk header '%s'", header)
+	}
+
+	return before, after, nil
+}
+
+// gitHeaderPath takes the name before the patch out of the "a/old b/new"
+// part of a "diff --git" line, for changes without ---/+++ lines, such as
+// to binary files or modes.
+func gitHeaderPath(names string) string {
+	if strings.HasPrefix(names, `"`) {
+		if quoted, err := strconv.QuotedPrefix(names); err == nil {
+			return patchPath(quoted, "a/")
+		}
+	}
+
+	// The names are usually the same, which settles where any spaces split
+	if n := len(names); n%2 == 1 && strings.HasPrefix(names[n/2+1:], "b/") &&
+		names[2:n/2] == names[n/2+3:] {
+		return patchPath(names[:n/2], "a/")
+	}
+	if i := strings.Index(names, " b/"); i >= 0 {
+		return patchPath(names[:i], "a/")
+	}
+
+	return patchPath(names, "a/")
+}
+
+// patchPath cleans up a name from a patch header: unquoting it, dropping any
+// timestamp after a tab, and stripping the prefix git adds.
+func patchPath(p, prefix string) string {
+	if i := strings.Index(p, "\t"); i >= 0 {
+		p = p[:i]
+	}
+	if strings.HasPrefix(p, `"`) {
+		if unquoted, err := strconv.Unquote(p); err == nil {
+			p = unquoted
+		}
+	}
+
+	return strings.TrimPrefix(p, prefix)
+}
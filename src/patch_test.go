@@ -0,0 +1,110 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPatchFiles(t *testing.T) {
+	patch := `From 1234 Mon Sep 17 00:00:00 2001
+Subject: [PATCH] Fix things
+
+---
+ lib.go | 2 +-
+
+diff --git a/lib.go b/lib.go
+index 1111111..2222222 100644
+--- a/lib.go
++++ b/lib.go
+@@ -1,2 +1,2 @@
+ a
+--- looks like a header
++++ but isn't
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package main
+diff --git a/old name.go b/renamed.go
+similarity index 90%
+rename from old name.go
+rename to renamed.go
+--- a/old name.go
++++ b/renamed.go
+@@ -1 +1 @@
+-x
++y
+diff --git a/logo with space.png b/logo with space.png
+index 4444444..5555555 100644
+Binary files a/logo with space.png and b/logo with space.png differ
+diff --git "a/t\303\251st.go" "b/t\303\251st.go"
+deleted file mode 100644
+--- "a/t\303\251st.go"
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+`
+
+	files, err := patchFiles(strings.NewReader(patch))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing patch: %v\n", err)
+	}
+
+	expected := []string{"lib.go", "old name.go", "logo with space.png", "tést.go"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Got files %q, expected %q\n", files, expected)
+	}
+
+	// Plain unified diffs have no git headers at all
+	plain := "--- a/one.go\t2020-03-10 10:00:00\n+++ b/one.go\n@@ -1 +1 @@\n-1\n+2\n" +
+		"--- a/two.go\n+++ b/two.go\n@@ -1,2 +1 @@\n 1\n-2\n"
+	if files, err = patchFiles(strings.NewReader(plain)); err != nil {
+		t.Fatalf("Unexpected error parsing patch: %v\n", err)
+	}
+	if expected := []string{"one.go", "two.go"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Got files %q, expected %q\n", files, expected)
+	}
+}
+
+func TestFindReviewersForPatch(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// The release branch was cut before george rewrote lib.go on master
+	f.commit("abe@git-reviewer.com", map[string]string{"lib.go": "1\n2\n3\n", "util.go": "u\n"})
+	f.git("branch", "release")
+	f.commit("george@git-reviewer.com", map[string]string{"lib.go": "a\nb\nc\nd\n", "master.go": "m\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"lib.go": "a\nb\nc\nd\ne\n", "master.go": "m\nn\n"})
+
+	patch := f.git("format-patch", "--stdout", "master..feature")
+
+	cases := []struct {
+		Base     string
+		Expected string
+		Missing  string
+	}{
+		{"", "george@git-reviewer.com", "abe@git-reviewer.com"},
+		// master.go isn't on the release branch, so only lib.go counts
+		{"release", "abe@git-reviewer.com", "george@git-reviewer.com"},
+	}
+
+	for _, c := range cases {
+		out, err := f.counter().FindReviewersForPatch(strings.NewReader(patch+"\n"), c.Base)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers against '%s': %v\n", c.Base, err)
+		}
+
+		if !strings.Contains(out, c.Expected) || strings.Contains(out, c.Missing) {
+			t.Errorf("Expected only %s to be suggested against '%s', got:\n%s", c.Expected, c.Base, out)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"sort"
+)
+
+// QuorumReviewers suggests n reviewers for the files changed in this branch
+// that can give independent approvals. Reviewers who have mostly worked on the
+// same files as each other are likely to share blind spots, so after taking
+// the most experienced reviewer, each further pick favors experience on the
+// parts of the diff the existing picks don't know.
+//
+// Each candidate is scored by their share of owned lines scaled down by their
+// overlap with the reviewers already chosen:
+//
+//	score = Percentage * (1 - max overlap with any chosen reviewer)
+//
+// where the overlap of two reviewers is the Jaccard index of the sets of
+// changed files they own lines in. Fewer than n reviewers are returned when
+// there aren't enough collaborators on the changed files.
+//
+// n takes the place of MaxReviewers, and the quorum's own order takes the
+// place of PreferFastReviewers and PreferWorkingHours. PostProcess sees the
+// picks in that order, and its order stands. As with FindReviewers, a short
+// quorum under StrictMaxReviewers comes back with ErrInsufficientReviewers,
+// and with every collaborator excluded the quorum is empty.
+func (r *ContributionCounter) QuorumReviewers(n int) (Stats, error) {
+	defer r.startRun()()
+
+	files, err := r.FindFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	final, byFile, err := r.candidates(files)
+	if _, ok := err.(allExcludedErr); ok {
+		return Stats{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(final) == 0 {
+		return nil, noReviewersErr{}
+	}
+
+	quorum := chooseQuorum(n, final, byFile)
+	if r.PostProcess != nil {
+		if quorum = r.PostProcess(quorum); len(quorum) > n {
+			quorum = quorum[:n]
+		}
+		if len(quorum) == 0 {
+			return nil, noReviewersErr{}
+		}
+	}
+	assignRanks(quorum)
+
+	if r.StrictMaxReviewers && len(quorum) < n {
+		err = ErrInsufficientReviewers{Found: len(quorum), Wanted: n}
+	}
+	if describeErr := r.describe(quorum, files); describeErr != nil {
+		return nil, describeErr
+	}
+
+	return quorum, err
+}
+
+// chooseQuorum greedily picks n mutually independent reviewers from s.
+func chooseQuorum(n int, s Stats, byFile contributions) Stats {
+	owned := make(map[string]map[string]bool)
+	for path, lines := range byFile {
+		for _, line := range lines {
+			if owned[line.author] == nil {
+				owned[line.author] = make(map[string]bool)
+			}
+			owned[line.author][path] = true
+		}
+	}
+
+	remaining := make(Stats, len(s))
+	copy(remaining, s)
+	sort.Stable(sort.Reverse(remaining))
+
+	var quorum Stats
+	for len(quorum) < n && len(remaining) > 0 {
+		best, bestScore := 0, -1.0
+		for i, candidate := range remaining {
+			var overlap float64
+			for _, chosen := range quorum {
+				if o := jaccard(owned[candidate.Reviewer], owned[chosen.Reviewer]); o > overlap {
+					overlap = o
+				}
+			}
+
+			if score := candidate.Percentage * (1 - overlap); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		quorum = append(quorum, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+
+	return quorum
+}
+
+// jaccard measures how similar two sets are as the size of their intersection
+// over the size of their union.
+func jaccard(a, b map[string]bool) float64 {
+	var shared int
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(union)
+}
@@ -0,0 +1,84 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+)
+
+func TestQuorumReviewers(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// abe and bob have only ever worked on the same two files, so a second
+	// approval from bob tells us little that abe's doesn't. carol owns less, but
+	// is the only one who knows c.go.
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"a.go": "1\n2\n3\n4\n", "b.go": "1\n2\n3\n4\n",
+	})
+	f.commit("bob@git-reviewer.com", map[string]string{
+		"a.go": "1\n2\n3\n4\n5\n6\n7\n", "b.go": "1\n2\n3\n4\n5\n6\n7\n",
+	})
+	f.commit("carol@git-reviewer.com", map[string]string{"c.go": "1\n2\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"a.go": "changed\n", "b.go": "changed\n", "c.go": "changed\n",
+	})
+
+	quorum, err := f.counter().QuorumReviewers(2)
+	if err != nil {
+		t.Fatalf("Unexpected error choosing quorum: %v\n", err)
+	}
+
+	if l := len(quorum); l != 2 {
+		t.Fatalf("Got a quorum of %d, expected 2\n", l)
+	}
+	if r := quorum[0].Reviewer; r != "abe@git-reviewer.com" {
+		t.Errorf("Got first reviewer %s, expected abe@git-reviewer.com\n", r)
+	}
+	if r := quorum[1].Reviewer; r != "carol@git-reviewer.com" {
+		t.Errorf("Got second reviewer %s, expected carol@git-reviewer.com\n", r)
+	}
+	for i, stat := range quorum {
+		if stat.Rank != i+1 {
+			t.Errorf("Got rank %d for %s, expected %d\n", stat.Rank, stat.Reviewer, i+1)
+		}
+		if len(stat.Name) == 0 || len(stat.Reasons) == 0 {
+			t.Errorf("Got %s undescribed: name %q, reasons %v\n", stat.Reviewer, stat.Name, stat.Reasons)
+		}
+	}
+
+	// Like FindReviewerStats, excluding everyone leaves nobody to suggest
+	// rather than failing.
+	r := f.counter()
+	r.ExcludedReviewers = []string{"abe", "bob", "carol"}
+	quorum, err = r.QuorumReviewers(2)
+	if err != nil || len(quorum) != 0 {
+		t.Errorf("Got quorum %v and error %v with everyone excluded, expected neither\n", quorum, err)
+	}
+
+	r = f.counter()
+	r.StrictMaxReviewers = true
+	quorum, err = r.QuorumReviewers(4)
+	if _, ok := err.(ErrInsufficientReviewers); !ok || len(quorum) != 3 {
+		t.Errorf("Got quorum of %d and error %v, expected 3 and ErrInsufficientReviewers\n", len(quorum), err)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	a := map[string]bool{"a.go": true, "b.go": true}
+	b := map[string]bool{"b.go": true, "c.go": true}
+
+	if j := jaccard(a, a); j != 1 {
+		t.Errorf("Got overlap %f for identical sets, expected 1\n", j)
+	}
+	if j := jaccard(a, b); j != 1.0/3.0 {
+		t.Errorf("Got overlap %f, expected %f\n", j, 1.0/3.0)
+	}
+	if j := jaccard(a, nil); j != 0 {
+		t.Errorf("Got overlap %f for disjoint sets, expected 0\n", j)
+	}
+}
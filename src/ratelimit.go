@@ -0,0 +1,81 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiterMu guards creating a counter's rateLimiter, which happens on the
+// first git command and may race between the goroutines blaming files.
+var limiterMu sync.Mutex
+
+// rateLimiter is a token bucket holding a single token, refilled at rate
+// per second, so waiters are let through evenly spaced rather than in
+// bursts.
+type rateLimiter struct {
+	mu   sync.Mutex
+	rate float64
+	// next is when the token is next available
+	next time.Time
+}
+
+// wait blocks until the limiter lets another command through, or returns
+// ctx.Err() if ctx ends first.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	delay := time.Until(l.reserve(time.Now()))
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve takes the token for a command asking at now and returns when the
+// command may start. Taking the token reserves the next one for whoever comes
+// after, even before the wait for this one is over.
+func (l *rateLimiter) reserve(now time.Time) time.Time {
+	interval := time.Duration(float64(time.Second) / l.rate)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(interval)
+
+	return at
+}
+
+// throttle waits for RateLimit to allow another git command under the
+// counter's context. Copies of the counter made after its first command
+// share its limiter.
+func (r *ContributionCounter) throttle() error {
+	if r.RateLimit <= 0 {
+		return nil
+	}
+
+	limiterMu.Lock()
+	if r.limiter == nil || r.limiter.rate != r.RateLimit {
+		r.limiter = &rateLimiter{rate: r.RateLimit}
+	}
+	l := r.limiter
+	limiterMu.Unlock()
+
+	return l.wait(r.context())
+}
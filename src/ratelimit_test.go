@@ -0,0 +1,120 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	dir, err := ioutil.TempDir("", "git-reviewer-starts")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+	starts := filepath.Join(dir, "starts")
+
+	// Every git command notes when it started, in nanoseconds
+	r := f.counter()
+	wrapGit(r, starts, `date +%s%N >> "$0"; exec "$@"`)
+	r.RateLimit = 20
+	r.Concurrency = 4
+
+	if _, err := r.FindReviewerStats([]string{"main.go", "util.go", "doc.go"}); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	out, err := ioutil.ReadFile(starts)
+	if err != nil {
+		t.Fatalf("Unable to read command starts: %v\n", err)
+	}
+
+	var times []int64
+	for _, line := range strings.Fields(string(out)) {
+		n, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			t.Fatalf("Unexpected start time '%s': %v\n", line, err)
+		}
+		times = append(times, n)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	if len(times) < 3 {
+		t.Fatalf("Got %d git commands, expected enough to measure\n", len(times))
+	}
+
+	// Starting a process takes a moment after the limiter lets it through,
+	// so single gaps vary, but over the whole run it evens out
+	interval := 50 * time.Millisecond
+	span := time.Duration(times[len(times)-1] - times[0])
+	if expected := time.Duration(len(times)-1)*interval - 20*time.Millisecond; span < expected {
+		t.Errorf("Got %d git commands over %v, expected at least %v\n", len(times), span, expected)
+	}
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := &rateLimiter{rate: 20}
+	start := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	ms := time.Millisecond
+
+	cases := []struct {
+		Asked, Expected time.Duration
+	}{
+		// The first goes right away
+		{0, 0},
+		// A burst is spread out 50ms apart
+		{0, 50 * ms},
+		{10 * ms, 100 * ms},
+		{20 * ms, 150 * ms},
+		// After a lull there is no backlog to wait behind
+		{500 * ms, 500 * ms},
+		{510 * ms, 550 * ms},
+	}
+
+	for _, c := range cases {
+		if at := l.reserve(start.Add(c.Asked)); at.Sub(start) != c.Expected {
+			t.Errorf("Got a start at %v when asking at %v, expected %v\n", at.Sub(start), c.Asked, c.Expected)
+		}
+	}
+}
+
+func TestRateLimitContext(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	r.RateLimit = 0.1
+
+	// The first command goes right away, and the next would wait ten seconds
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := r.withContext(ctx, func() error {
+		if _, err := r.git("rev-parse", "HEAD"); err != nil {
+			t.Fatalf("Unexpected error from the first command: %v\n", err)
+		}
+		_, err := r.git("rev-parse", "HEAD")
+		return err
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("Got error '%v', expected the context's\n", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Took %v to give up, expected to stop waiting promptly\n", elapsed)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"strings"
+	"time"
+)
+
+// Reason codes explain why a reviewer was suggested, for integrations that
+// need something more stable than prose. A suggestion may carry several.
+const (
+	// ReasonLineOwnership: they last changed lines in the changed files, and
+	// experience is weighed by owned lines (WeightBlame).
+	ReasonLineOwnership = "LINE_OWNERSHIP"
+	// ReasonHighCommitCount: they committed to the changed files, and
+	// experience is weighed by commits (WeightCommits).
+	ReasonHighCommitCount = "HIGH_COMMIT_COUNT"
+	// ReasonLinesChanged: they changed lines in the changed files, and
+	// experience is weighed by lines changed (WeightLines).
+	ReasonLinesChanged = "LINES_CHANGED"
+	// ReasonRecentActivity: their latest counted work is from the last
+	// recentActivityDays days.
+	ReasonRecentActivity = "RECENT_ACTIVITY"
+	// ReasonBroadCoverage: they worked on at least half of several changed
+	// files.
+	ReasonBroadCoverage = "BROAD_COVERAGE"
+	// ReasonCodeOwner: CODEOWNERS lists them for one of the changed files.
+	ReasonCodeOwner = "CODEOWNER"
+	// ReasonWorkingHours: PreferWorkingHours moved them up because it is
+	// around when they usually commit.
+	ReasonWorkingHours = "WORKING_HOURS"
+)
+
+// recentActivityDays is how recent work must be for ReasonRecentActivity.
+const recentActivityDays = 30
+
+// describe fills in the details of the suggestions for the paths that go
+// beyond their scores: their names and Reasons, and the precision String
+// shows their scores at.
+func (r *ContributionCounter) describe(top Stats, paths []string) error {
+	for _, stat := range top {
+		stat.precision = r.ScorePrecision
+	}
+	if err := r.nameReviewers(top); err != nil {
+		return err
+	}
+
+	return r.explain(top, paths)
+}
+
+// explain fills in the Reasons of the suggestions for the paths.
+func (r *ContributionCounter) explain(top Stats, paths []string) error {
+	paths = r.scoredPaths(paths)
+
+	owners, err := r.FindOwners(paths)
+	if err != nil {
+		return err
+	}
+	declared := declaredOwners(owners)
+
+	now := r.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	recent := now.AddDate(0, 0, -recentActivityDays).Format("2006-01-02")
+
+	for _, stat := range top {
+		var reasons []string
+		if stat.Count > 0 {
+			switch r.Weight {
+			case WeightCommits:
+				reasons = append(reasons, ReasonHighCommitCount)
+			case WeightLines:
+				reasons = append(reasons, ReasonLinesChanged)
+			default:
+				reasons = append(reasons, ReasonLineOwnership)
+			}
+		}
+		if stat.LastCommit >= recent {
+			reasons = append(reasons, ReasonRecentActivity)
+		}
+		if len(paths) > 1 && 2*stat.Files >= len(paths) {
+			reasons = append(reasons, ReasonBroadCoverage)
+		}
+		if r.isDeclaredOwner(declared, stat.Reviewer) {
+			reasons = append(reasons, ReasonCodeOwner)
+		}
+		if r.awakeBoost(stat.Reviewer) > 1 {
+			reasons = append(reasons, ReasonWorkingHours)
+		}
+
+		stat.Reasons = reasons
+	}
+
+	return nil
+}
+
+// declaredOwners gathers everyone CODEOWNERS names, as lowercase emails or
+// handles without the "@".
+func declaredOwners(owners map[string][]string) map[string]bool {
+	declared := make(map[string]bool)
+	for _, list := range owners {
+		for _, owner := range list {
+			declared[strings.ToLower(strings.TrimPrefix(owner, "@"))] = true
+		}
+	}
+
+	return declared
+}
+
+// isDeclaredOwner matches a reviewer against declared owners by email, or by
+// the handle Handles resolves for them when it is set.
+func (r *ContributionCounter) isDeclaredOwner(declared map[string]bool, reviewer string) bool {
+	if declared[strings.ToLower(reviewer)] {
+		return true
+	}
+
+	if r.Handles != nil {
+		if handle, ok := r.Handles(reviewer); ok {
+			return declared[strings.ToLower(strings.TrimPrefix(handle, "@"))]
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReasons(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.clock = f.clock.AddDate(-2, 0, 0)
+	f.commitAt("abe@git-reviewer.com", f.clock.Add(12*time.Hour), map[string]string{
+		"a.go": "1\n2\n", "b.go": "1\n", "c.go": "1\n",
+	})
+	f.clock = f.clock.AddDate(2, 0, 0)
+	now := f.clock.Add(time.Minute)
+	f.commitAt("george@git-reviewer.com", now, map[string]string{
+		"a.go":       "1\n2\n3\n",
+		"CODEOWNERS": "c.go george@git-reviewer.com\n",
+	})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n", "b.go": "1\n2\n", "c.go": "1\n2\n"})
+
+	paths := []string{"a.go", "b.go", "c.go"}
+
+	cases := []struct {
+		Weight       Weight
+		WorkingHours bool
+		Expected     map[string][]string
+	}{
+		{WeightBlame, false, map[string][]string{
+			"abe@git-reviewer.com":    {ReasonLineOwnership, ReasonBroadCoverage},
+			"george@git-reviewer.com": {ReasonLineOwnership, ReasonRecentActivity, ReasonCodeOwner},
+		}},
+		{WeightCommits, false, map[string][]string{
+			"abe@git-reviewer.com":    {ReasonHighCommitCount, ReasonBroadCoverage},
+			"george@git-reviewer.com": {ReasonHighCommitCount, ReasonRecentActivity, ReasonCodeOwner},
+		}},
+		// It's the middle of abe's night, but around when george commits
+		{WeightBlame, true, map[string][]string{
+			"abe@git-reviewer.com":    {ReasonLineOwnership, ReasonBroadCoverage},
+			"george@git-reviewer.com": {ReasonLineOwnership, ReasonRecentActivity, ReasonCodeOwner, ReasonWorkingHours},
+		}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Since = "2000-01-01"
+		r.Weight = c.Weight
+		r.PreferWorkingHours = c.WorkingHours
+		r.Now = now
+
+		stats, err := r.FindReviewerStats(paths)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		reasons := make(map[string][]string)
+		for _, stat := range stats {
+			reasons[stat.Reviewer] = stat.Reasons
+		}
+		if !reflect.DeepEqual(reasons, c.Expected) {
+			t.Errorf("Got reasons %v with weight %d and working hours %v, expected %v\n",
+				reasons, c.Weight, c.WorkingHours, c.Expected)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Contribution is a single commit's change to a single file.
+type Contribution struct {
+	Author  string
+	Email   string
+	File    string
+	SHA     string
+	Added   int64
+	Deleted int64
+	When    time.Time
+}
+
+// recordFormat separates commits with a NUL and their fields with a unit
+// separator, neither of which can appear in names or emails.
+const recordFormat = "--format=%x00%H%x1f%an%x1f%ae%x1f%aI"
+
+// ContributionRecords returns one Contribution for every time a commit touched
+// one of the paths, from the history of the base back to Since. These are the
+// raw rows the scoring modes aggregate, exposed for callers who want to do
+// their own modeling. Emails are normalized through the mailmap.
+func (r *ContributionCounter) ContributionRecords(paths []string) ([]Contribution, error) {
+	defer r.startRun()()
+	return r.records(paths)
+}
+
+// AuthorFootprint counts the commits one author made to each of the paths,
+// for contributors curious about their own footprint on a change. Paths the
+// author never committed to are left out.
+func (r *ContributionCounter) AuthorFootprint(email string, paths []string) (map[string]int, error) {
+	defer r.startRun()()
+
+	records, err := r.records(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	email = reviewerKey(email, r.Mailmap)
+	footprint := make(map[string]int)
+	for _, rec := range records {
+		if rec.Email == email {
+			footprint[rec.File]++
+		}
+	}
+
+	return footprint, nil
+}
+
+// AuthorsPerFile lists the distinct reviewers with experience in each of the
+// paths, without saying how much, for coverage dashboards. Experience is
+// found as for FindReviewerStats, by Weight and since Since, but nobody is
+// excluded. Authors are sorted, and paths nobody has experience with are
+// left out.
+func (r *ContributionCounter) AuthorsPerFile(paths []string) (map[string][]string, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	byFile, _, err := r.experience(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make(map[string][]string)
+	for path, lines := range byFile {
+		seen := make(map[string]bool)
+		for _, line := range lines {
+			if !seen[line.author] {
+				seen[line.author] = true
+				authors[path] = append(authors[path], line.author)
+			}
+		}
+		sort.Strings(authors[path])
+	}
+
+	return authors, nil
+}
+
+// records reads the contributions to the paths from the history of the base
+// through the Backend, and applies the mailmap and Until to them.
+func (r *ContributionCounter) records(paths []string) ([]Contribution, error) {
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := r.backend().Log(r, base.Hash, r.since(), paths)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		records[i].Email = reviewerKey(records[i].Email, r.Mailmap)
+	}
+	if len(r.Until) == 0 {
+		return records, nil
+	}
+
+	// Compare days as blame does rather than trusting git's date parsing
+	var kept []Contribution
+	for _, rec := range records {
+		if r.day(rec.When) <= r.Until {
+			kept = append(kept, rec)
+		}
+	}
+
+	return kept, nil
+}
+
+// parseRecords reads the output of git log with recordFormat and --numstat.
+func parseRecords(out []byte) ([]Contribution, error) {
+	var (
+		current Contribution
+		records []Contribution
+	)
+
+	scn := bufio.NewScanner(bytes.NewReader(out))
+	for scn.Scan() {
+		line := scn.Text()
+
+		if strings.HasPrefix(line, "\x00") {
+			fields := strings.Split(line[1:], "\x1f")
+			if len(fields) != 4 {
+				return nil, errors.Errorf("unexpected commit header '%s'", line)
+			}
+
+			when, err := time.Parse(time.RFC3339, fields[3])
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to parse commit date")
+			}
+
+			current = Contribution{
+				Author: fields[1],
+				Email:  fields[2],
+				SHA:    fields[0],
+				When:   when,
+			}
+			continue
+		}
+
+		if len(line) == 0 {
+			continue
+		}
+
+		// numstat lines look like "<added>\t<deleted>\t<path>", with dashes
+		// instead of counts for binary files.
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			return nil, errors.Errorf("unexpected numstat line '%s'", line)
+		}
+
+		record := current
+		record.Added, _ = strconv.ParseInt(fields[0], 10, 64)
+		record.Deleted, _ = strconv.ParseInt(fields[1], 10, 64)
+		record.File = fields[2]
+		records = append(records, record)
+	}
+
+	return records, scn.Err()
+}
+
+// managedLogArgs are git log options records relies on to get output it can
+// parse, or sets itself, so they can't be given in ExtraLogArgs.
+var managedLogArgs = []string{
+	"--format", "--pretty", "--oneline", "--numstat", "--stat", "--shortstat",
+	"--name-only", "--name-status", "--patch", "-p", "-z", "--graph",
+	"--merges", "--since", "--after", "--follow", "--",
+}
+
+// unsafeLogArgs are git log options that write files, which ExtraLogArgs
+// must never be able to do.
+var unsafeLogArgs = []string{"--output", "-o"}
+
+// checkExtraLogArgs rejects ExtraLogArgs that conflict with managedLogArgs or
+// are among unsafeLogArgs.
+func checkExtraLogArgs(extra []string) error {
+	for _, arg := range extra {
+		// Short options take their value attached, as in -ofile
+		for _, unsafe := range unsafeLogArgs {
+			if arg == unsafe || strings.HasPrefix(arg, unsafe+"=") ||
+				(!strings.HasPrefix(unsafe, "--") && strings.HasPrefix(arg, unsafe)) {
+				return errors.Errorf("extra git log argument '%s' would write a file", arg)
+			}
+		}
+
+		for _, managed := range managedLogArgs {
+			if arg == managed || strings.HasPrefix(arg, managed+"=") {
+				return errors.Errorf("extra git log argument '%s' conflicts with one git-reviewer sets", arg)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContributionRecords(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	first := f.commit("abe@git-reviewer.com", map[string]string{
+		"a.go": "1\n2\n3\n", "b.go": "1\n",
+	})
+	second := f.commit("george@git-reviewer.com", map[string]string{
+		"a.go": "1\n2\n4\n5\n",
+	})
+	f.commit("george@git-reviewer.com", map[string]string{"ignored.go": "1\n"})
+
+	records, err := f.counter().ContributionRecords([]string{"a.go", "b.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error reading records: %v\n", err)
+	}
+
+	// git log lists the newest commits first
+	expected := []Contribution{
+		{Author: "george", Email: "george@git-reviewer.com", File: "a.go", SHA: second, Added: 2, Deleted: 1},
+		{Author: "abe", Email: "abe@git-reviewer.com", File: "a.go", SHA: first, Added: 3},
+		{Author: "abe", Email: "abe@git-reviewer.com", File: "b.go", SHA: first, Added: 1},
+	}
+
+	if len(records) != len(expected) {
+		t.Fatalf("Got %d records, expected %d: %+v\n", len(records), len(expected), records)
+	}
+
+	for i, e := range expected {
+		actual := records[i]
+		if actual.When.IsZero() {
+			t.Errorf("Record %d is missing its commit date\n", i)
+		}
+
+		actual.When = e.When
+		if actual != e {
+			t.Errorf("Got record %+v, expected %+v\n", actual, e)
+		}
+	}
+}
+
+func TestAuthorFootprint(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("jane+work@git-reviewer.com", map[string]string{"a.go": "1\n", "b.go": "1\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n", "c.go": "1\n"})
+	f.commit("jane+work@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commit("jane+work@git-reviewer.com", map[string]string{"d.go": "1\n"})
+
+	footprint, err := f.counter().AuthorFootprint("jane+work@git-reviewer.com",
+		[]string{"a.go", "b.go", "c.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding footprint: %v\n", err)
+	}
+
+	expected := map[string]int{"a.go": 2, "b.go": 1}
+	if !reflect.DeepEqual(footprint, expected) {
+		t.Errorf("Got footprint %v, expected %v\n", footprint, expected)
+	}
+}
+
+func TestAuthorsPerFile(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n", "b.go": "1\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commit("tom@git-reviewer.com", map[string]string{"b.go": "x\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"c.go": "1\n"})
+
+	cases := []struct {
+		Weight   Weight
+		Expected map[string][]string
+	}{
+		// tom replaced george's only line in b.go
+		{WeightBlame, map[string][]string{
+			"a.go": {"abe@git-reviewer.com", "george@git-reviewer.com"},
+			"b.go": {"tom@git-reviewer.com"},
+		}},
+		{WeightCommits, map[string][]string{
+			"a.go": {"abe@git-reviewer.com", "george@git-reviewer.com"},
+			"b.go": {"george@git-reviewer.com", "tom@git-reviewer.com"},
+		}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Weight = c.Weight
+
+		authors, err := r.AuthorsPerFile([]string{"a.go", "b.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding authors: %v\n", err)
+		}
+		if !reflect.DeepEqual(authors, c.Expected) {
+			t.Errorf("Got authors %v with weight %d, expected %v\n", authors, c.Weight, c.Expected)
+		}
+	}
+}
+
+func TestParseRecordsLargeCounts(t *testing.T) {
+	// Generated files can churn more lines than fit in a 32-bit int
+	out := []byte("\x00abc123\x1fabe\x1fabe@git-reviewer.com\x1f2017-06-01T12:00:00Z\n" +
+		"\n" +
+		"4294967296\t3000000000\tgenerated.go\n")
+
+	records, err := parseRecords(out)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing records: %v\n", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Got %d records, expected 1: %+v\n", len(records), records)
+	}
+
+	if records[0].Added != 4294967296 || records[0].Deleted != 3000000000 {
+		t.Errorf("Got %d added and %d deleted, expected the full counts\n",
+			records[0].Added, records[0].Deleted)
+	}
+}
+
+func TestExtraLogArgs(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n"})
+	f.git("checkout", "-q", "-b", "other")
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n"})
+	f.git("checkout", "-q", "master")
+
+	// Log the arguments git gets, one per line
+	log := filepath.Join(f.dir, ".git", "args.log")
+	r := f.counter()
+	wrapGit(r, log, `printf '%s\n' "$@" >> "$0"; exec "$@"`)
+	r.ExtraLogArgs = []string{"--all", "--author=george@git-reviewer.com"}
+
+	records, err := r.ContributionRecords([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error reading records: %v\n", err)
+	}
+	if len(records) != 1 || records[0].Email != "george@git-reviewer.com" {
+		t.Errorf("Got records %+v, expected george's commit on the other branch\n", records)
+	}
+
+	out, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatalf("Unable to read logged arguments: %v\n", err)
+	}
+	args := strings.Split(string(out), "\n")
+	found := 0
+	for _, arg := range args {
+		if arg == "--all" || arg == "--author=george@git-reviewer.com" {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("Got arguments %q, expected both extra arguments intact\n", args)
+	}
+}
+
+func TestExtraLogArgsConflicts(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	out := filepath.Join(f.dir, "overwritten")
+	for _, arg := range []string{"--format=%H", "--numstat", "--since=2017-01-01", "--",
+		"--output=" + out, "--output", "-o" + out} {
+		r := f.counter()
+		r.ExtraLogArgs = []string{"--all", arg}
+
+		if _, err := r.ContributionRecords([]string{"main.go"}); err == nil {
+			t.Errorf("Expected an error for extra argument %s\n", arg)
+		}
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Errorf("Expected extra arguments to never write %s\n", out)
+	}
+}
+
+func TestSince(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commitAt("abe@git-reviewer.com", time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), map[string]string{"a.go": "1\n"})
+	f.commitAt("george@git-reviewer.com", time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC), map[string]string{"a.go": "1\n2\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+
+	log := filepath.Join(f.dir, ".git", "commands.log")
+	for _, weight := range []Weight{WeightBlame, WeightCommits} {
+		os.Remove(log)
+
+		r := f.counter()
+		r.Since = "2020-06-01"
+		r.Weight = weight
+		wrapGit(r, log, `printf '%s\n' "$*" >> "$0"; exec "$@"`)
+
+		// Abe's work is from before Since
+		stats, err := r.FindReviewerStats([]string{"a.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers with weight %d: %v\n", weight, err)
+		}
+		if len(stats) != 1 || stats[0].Reviewer != "george@git-reviewer.com" {
+			t.Errorf("Got reviewers %v with weight %d, expected george\n", stats, weight)
+		}
+
+		// Blame has no such option, so its lines are filtered afterwards
+		if weight == WeightCommits {
+			out, err := ioutil.ReadFile(log)
+			if err != nil {
+				t.Fatalf("Wrapper never ran: %v\n", err)
+			}
+			if !strings.Contains(string(out), "--since 2020-06-01") {
+				t.Errorf("Expected git to be asked for commits since 2020-06-01, got:\n%s", out)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// renamedPaths finds which of the paths got their names at rev by being
+// renamed since the day since, so their history from before that is under
+// other names.
+func renamedPaths(r *ContributionCounter, rev plumbing.Hash, since string, paths []string) (map[string]bool, error) {
+	out, err := r.git("log", "--no-merges", "-M", "--diff-filter=R", "--name-status",
+		"--format=%x00", "--since", r.gitDate(since), rev.String(), "--")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git log command")
+	}
+
+	wanted := make(map[string]bool)
+	for _, p := range paths {
+		wanted[p] = true
+	}
+
+	// Each rename is a line "R<similarity>\told\tnew"
+	renamed := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) == 3 && strings.HasPrefix(fields[0], "R") && wanted[fields[2]] {
+			renamed[fields[2]] = true
+		}
+	}
+
+	return renamed, nil
+}
+
+// renamedPath reads the new name out of a numstat path that may show a
+// rename, either as "old => new" or with the parts that changed in braces,
+// like "src/{old => new}/main.go".
+func renamedPath(p string) string {
+	open, close := strings.Index(p, "{"), strings.LastIndex(p, "}")
+	if open >= 0 && close > open && strings.Contains(p[open:close], " => ") {
+		inner := p[open+1 : close]
+		renamed := inner[strings.Index(inner, " => ")+len(" => "):]
+
+		// An empty side leaves a doubled or leading slash behind
+		p = p[:open] + renamed + p[close+1:]
+		p = strings.Replace(p, "//", "/", -1)
+		return strings.TrimPrefix(p, "/")
+	}
+
+	if i := strings.Index(p, " => "); i >= 0 {
+		return p[i+len(" => "):]
+	}
+
+	return p
+}
@@ -0,0 +1,89 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFollowRenames(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// abe wrote the file and george worked on it under its first name; tom
+	// moved it twice, changing a line on the way
+	f.commit("abe@git-reviewer.com", map[string]string{"old.go": "1\n2\n3\n4\n5\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"old.go": "1\n2\n3\n4\n5\n6\n"})
+	f.git("mv", "old.go", "mid.go")
+	f.commit("tom@git-reviewer.com", nil)
+	if err := os.Mkdir(filepath.Join(f.dir, "lib"), 0755); err != nil {
+		t.Fatalf("Unable to create lib: %v\n", err)
+	}
+	f.git("mv", "mid.go", "lib/new.go")
+	f.commit("tom@git-reviewer.com", map[string]string{"lib/new.go": "1\n2\n3\n4\n5\n6\n7\n"})
+	// An unrelated file that never moved, and one that took the old name
+	f.commit("bob@git-reviewer.com", map[string]string{"other.go": "x\ny\nz\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"old.go": "a\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"old.go": "a\nb\n"})
+
+	records, err := f.counter().ContributionRecords([]string{"lib/new.go", "other.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding records: %v\n", err)
+	}
+
+	commits := make(map[string][]string)
+	for _, rec := range records {
+		commits[rec.File] = append(commits[rec.File], rec.Email)
+	}
+	expected := map[string][]string{
+		"lib/new.go": {"tom@git-reviewer.com", "tom@git-reviewer.com", "george@git-reviewer.com", "abe@git-reviewer.com"},
+		"other.go":   {"bob@git-reviewer.com"},
+	}
+	if !reflect.DeepEqual(commits, expected) {
+		t.Errorf("Got commits %v, expected %v\n", commits, expected)
+	}
+
+	for _, weight := range []Weight{WeightBlame, WeightCommits} {
+		r := f.counter()
+		r.Weight = weight
+		r.MaxReviewers = 10
+
+		stats, err := r.FindReviewerStats([]string{"lib/new.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		found := false
+		for _, stat := range stats {
+			found = found || stat.Reviewer == "abe@git-reviewer.com"
+		}
+		if !found {
+			t.Errorf("Expected the original author among reviewers with weight %s, got %v\n",
+				weightNames[weight], stats)
+		}
+	}
+}
+
+func TestRenamedPath(t *testing.T) {
+	cases := map[string]string{
+		"main.go":                  "main.go",
+		"old.go => lib/new.go":     "lib/new.go",
+		"src/{old => new}/main.go": "src/new/main.go",
+		"src/{ => lib}/main.go":    "src/lib/main.go",
+		"src/{lib => }/main.go":    "src/main.go",
+		"{src => lib}/main.go":     "lib/main.go",
+		"cmd/{a.go => b.go}":       "cmd/b.go",
+	}
+
+	for numstat, expected := range cases {
+		if actual := renamedPath(numstat); actual != expected {
+			t.Errorf("Got %s for %s, expected %s\n", actual, numstat, expected)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Report holds the suggested reviewers for a set of changes along with
+// details about how much the suggestion can be trusted.
+type Report struct {
+	Reviewers Stats
+
+	// Confidence ranges from 0 to 1 and estimates how trustworthy the
+	// suggestion is, based on how much history it was drawn from. See
+	// confidence for the formula.
+	Confidence float64
+}
+
+// FindReport finds the top reviewers for the changed paths like
+// FindReviewerStats, and reports how confident we are in the suggestion. As
+// with FindReviewerStats, a short list with StrictMaxReviewers is reported
+// along with ErrInsufficientReviewers.
+func (r *ContributionCounter) FindReport(paths []string) (*Report, error) {
+	defer r.startRun()()
+
+	final, byFile, err := r.candidates(paths)
+	if _, ok := err.(allExcludedErr); ok {
+		return &Report{Reviewers: Stats{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	top, err := r.selectTop(final)
+	if _, ok := err.(ErrInsufficientReviewers); err != nil && !ok {
+		return nil, err
+	}
+	if describeErr := r.describe(top, paths); describeErr != nil {
+		return nil, describeErr
+	}
+
+	return &Report{Reviewers: top, Confidence: confidence(byFile)}, err
+}
+
+// reportFormats are the formats WriteReport can write, by file extension.
+var reportFormats = map[string]func(io.Writer, *Report) error{
+	".json": JSONFormatter{Indent: true}.Write,
+	".csv": func(w io.Writer, report *Report) error {
+		f := CSVFormatter{
+			Columns: []string{"reviewer", "count", "files", "share", "last_commit"},
+			Header:  true,
+		}
+		return f.Write(w, report.Reviewers)
+	},
+	".md": MarkdownFormatter{}.Write,
+}
+
+// WriteReport finds the report for the changed paths like FindReport and
+// saves it to a file for archival. The format follows the file's extension:
+// .json as with JSONFormatter, .csv as with CSVFormatter with a header and
+// every column but email, or .md as with MarkdownFormatter. Missing parent
+// directories are created, and an existing file is replaced.
+func (r *ContributionCounter) WriteReport(path string, paths []string) error {
+	defer r.startRun()()
+
+	write, ok := reportFormats[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return errors.Errorf("unknown report format for '%s', expected .json, .csv, or .md", path)
+	}
+
+	report, err := r.FindReport(paths)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "unable to create report directory")
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "unable to create report file")
+	}
+
+	if err := write(out, report); err != nil {
+		out.Close()
+		return errors.Wrap(err, "unable to write report")
+	}
+
+	return errors.Wrap(out.Close(), "unable to write report")
+}
+
+// Scales at which each signal in the confidence score reaches about 63% of
+// its contribution. Beyond a few multiples of these, more history barely
+// changes our confidence.
+const (
+	confidenceCommits = 10.0
+	confidenceAuthors = 3.0
+	confidenceDays    = 90.0
+)
+
+// confidence estimates how much to trust reviewers drawn from the counted
+// lines. It averages three saturating signals, each ranging from 0 to 1:
+//
+//	commits: 1 - e^(-distinct commits / 10)
+//	authors: 1 - e^(-distinct authors / 3)
+//	spread:  1 - e^(-days between oldest and newest line / 90)
+//
+// A single commit by a single author scores about 0.13, while dozens of
+// commits by several authors over a year approach 1.
+func confidence(byFile contributions) float64 {
+	var (
+		commits        = make(map[string]bool)
+		authors        = make(map[string]bool)
+		oldest, newest string
+	)
+
+	for _, lines := range byFile {
+		for _, line := range lines {
+			commits[line.rev] = true
+			authors[line.author] = true
+
+			if oldest == "" || line.date < oldest {
+				oldest = line.date
+			}
+			if line.date > newest {
+				newest = line.date
+			}
+		}
+	}
+
+	var days float64
+	from, errFrom := time.Parse("2006-01-02", oldest)
+	to, errTo := time.Parse("2006-01-02", newest)
+	if errFrom == nil && errTo == nil {
+		days = to.Sub(from).Hours() / 24
+	}
+
+	saturate := func(x, scale float64) float64 {
+		return 1 - math.Exp(-x/scale)
+	}
+
+	return (saturate(float64(len(commits)), confidenceCommits) +
+		saturate(float64(len(authors)), confidenceAuthors) +
+		saturate(days, confidenceDays)) / 3
+}
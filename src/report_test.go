@@ -0,0 +1,155 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfidenceRichHistory(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	authors := []string{"abe", "bob", "carol", "dave", "erin"}
+	start := time.Now().AddDate(-1, 0, 0)
+	content := ""
+	for i := 0; i < 40; i++ {
+		content += fmt.Sprintf("line %d\n", i)
+		email := authors[i%len(authors)] + "@git-reviewer.com"
+		f.commitAt(email, start.AddDate(0, 0, i*9), map[string]string{"a.go": content})
+	}
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": content + "new\n"})
+
+	r := f.counter()
+	r.Since = start.AddDate(0, 0, -1).Format("2006-01-02")
+
+	report, err := r.FindReport([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding report: %v\n", err)
+	}
+
+	if report.Confidence < 0.8 {
+		t.Errorf("Got confidence %.2f for rich history, expected at least 0.8\n",
+			report.Confidence)
+	}
+	if l := len(report.Reviewers); l != 3 {
+		t.Errorf("Got %d reviewers, expected 3\n", l)
+	}
+}
+
+func TestConfidenceSparseHistory(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "a\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": "b\n"})
+
+	report, err := f.counter().FindReport([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding report: %v\n", err)
+	}
+
+	if report.Confidence > 0.2 {
+		t.Errorf("Got confidence %.2f for sparse history, expected at most 0.2\n",
+			report.Confidence)
+	}
+
+	// A short list is still reported
+	r := f.counter()
+	r.StrictMaxReviewers = true
+	report, err = r.FindReport([]string{"a.go"})
+	if expected := (ErrInsufficientReviewers{Found: 1, Wanted: 3}); err != expected {
+		t.Errorf("Got error %v with StrictMaxReviewers, expected %v\n", err, expected)
+	}
+	if report == nil || len(report.Reviewers) != 1 || report.Reviewers[0].Name != "abe" {
+		t.Errorf("Got report %+v with StrictMaxReviewers, expected abe described\n", report)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	paths := []string{"main.go", "util.go", "doc.go"}
+	report, err := f.counter().FindReport(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error finding report: %v\n", err)
+	}
+
+	dir, err := ioutil.TempDir("", "git-reviewer-report")
+	if err != nil {
+		t.Fatalf("Unable to create report directory: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	read := func(name string) []byte {
+		p := filepath.Join(dir, "archive", "2017", name)
+		if err := f.counter().WriteReport(p, paths); err != nil {
+			t.Fatalf("Unexpected error writing %s: %v\n", name, err)
+		}
+
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatalf("Unable to read %s back: %v\n", name, err)
+		}
+		return data
+	}
+
+	var doc jsonReport
+	if err := json.Unmarshal(read("report.json"), &doc); err != nil {
+		t.Fatalf("Unable to parse JSON report: %v\n", err)
+	}
+	if doc.SchemaVersion != JSONSchemaVersion || len(doc.Reviewers) != len(report.Reviewers) {
+		t.Errorf("Got JSON report %+v, expected %d reviewers\n", doc, len(report.Reviewers))
+	}
+	for i, review := range doc.Reviewers {
+		if review.Reviewer != report.Reviewers[i].Reviewer || review.Count != report.Reviewers[i].Count {
+			t.Errorf("Got JSON reviewer %+v, expected %v\n", review, report.Reviewers[i])
+		}
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(string(read("report.csv")))).ReadAll()
+	if err != nil {
+		t.Fatalf("Unable to parse CSV report: %v\n", err)
+	}
+	if len(rows) != len(report.Reviewers)+1 || strings.Join(rows[0], ",") != "reviewer,count,files,share,last_commit" {
+		t.Fatalf("Got CSV rows %v, expected a header and %d reviewers\n", rows, len(report.Reviewers))
+	}
+	for i, stat := range report.Reviewers {
+		if rows[i+1][0] != stat.Reviewer || rows[i+1][1] != fmt.Sprint(stat.Count) {
+			t.Errorf("Got CSV row %v, expected %v\n", rows[i+1], stat)
+		}
+	}
+
+	md := strings.Split(strings.TrimSpace(string(read("REPORT.MD"))), "\n")
+	if len(md) != len(report.Reviewers)+4 || !strings.HasPrefix(md[len(md)-1], "Confidence: ") {
+		t.Fatalf("Got Markdown report:\n%s\nexpected a table of %d reviewers and the confidence\n",
+			strings.Join(md, "\n"), len(report.Reviewers))
+	}
+	for i, stat := range report.Reviewers {
+		expected := fmt.Sprintf("| %d | %s | %d |", stat.Rank, stat.Name+" <"+stat.Reviewer+">", stat.Count)
+		if !strings.HasPrefix(md[i+2], expected) {
+			t.Errorf("Got Markdown row '%s', expected it to start '%s'\n", md[i+2], expected)
+		}
+	}
+
+	if err := f.counter().WriteReport(filepath.Join(dir, "report.txt"), paths); err == nil {
+		t.Error("Expected an error for an unknown report format")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "report.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file for an unknown report format, got %v\n", err)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// revertRx finds the commit a revert undoes in the message git revert writes.
+var revertRx = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40})`)
+
+// revertedCommits finds every revert in the history of the base, returning
+// both the reverts and the commits they undo.
+func (r *ContributionCounter) revertedCommits() (map[string]bool, error) {
+	reverts, err := r.reverts()
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled := make(map[string]bool)
+	for sha, message := range reverts {
+		m := revertRx.FindStringSubmatch(message)
+		if m == nil {
+			continue
+		}
+
+		// Reverts made with abbreviated hashes still need to match the full
+		// hashes attributions carry.
+		original := m[1]
+		if len(original) < 40 {
+			full, err := r.fullHash(original)
+			if err != nil {
+				continue
+			}
+			original = full
+		}
+
+		cancelled[sha] = true
+		cancelled[original] = true
+	}
+
+	return cancelled, nil
+}
+
+// reverts finds the messages of the commits in the history of the base that
+// look like reverts, by hash.
+func (r *ContributionCounter) reverts() (map[string]string, error) {
+	base, err := r.baseCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	reverts := make(map[string]string)
+	if !r.runsGit() {
+		err := r.walkHistory(base.Hash, "", func(c *object.Commit) error {
+			if strings.Contains(c.Message, "This reverts commit") {
+				reverts[c.Hash.String()] = c.Message
+			}
+			return nil
+		})
+		return reverts, err
+	}
+
+	out, err := r.git("log", "--grep=This reverts commit", "--format=%x00%H%n%B", base.Hash.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to execute external git log command")
+	}
+
+	for _, entry := range strings.Split(string(out), "\x00") {
+		if parts := strings.SplitN(entry, "\n", 2); len(parts) == 2 {
+			reverts[parts[0]] = parts[1]
+		}
+	}
+
+	return reverts, nil
+}
+
+// fullHash expands an abbreviated commit hash, as long as it names just one
+// commit.
+func (r *ContributionCounter) fullHash(short string) (string, error) {
+	if r.runsGit() {
+		out, err := r.git("rev-parse", "--verify", "-q", short+"^{commit}")
+		return string(out), err
+	}
+
+	goGitMu.Lock()
+	defer goGitMu.Unlock()
+
+	iter, err := r.Repo.CommitObjects()
+	if err != nil {
+		return "", err
+	}
+
+	var found []string
+	err = iter.ForEach(func(c *object.Commit) error {
+		if h := c.Hash.String(); strings.HasPrefix(h, short) {
+			found = append(found, h)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(found) != 1 {
+		return "", errors.Errorf("%s names %d commits", short, len(found))
+	}
+
+	return found[0], nil
+}
+
+// dropCommits drops the attributions that come from any of the commits, such
+// as reverts and the commits they undo.
+func dropCommits(byFile contributions, cancelled map[string]bool) {
+	for path, lines := range byFile {
+		var kept []attribution
+		for _, line := range lines {
+			if !cancelled[line.rev] {
+				kept = append(kept, line)
+			}
+		}
+		byFile[path] = kept
+	}
+}
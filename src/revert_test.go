@@ -0,0 +1,58 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+)
+
+func TestNetChangesOnly(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\nx\n3\n"})
+	f.gitEnv([]string{
+		"GIT_AUTHOR_NAME=bob",
+		"GIT_AUTHOR_EMAIL=bob@git-reviewer.com",
+	}, "revert", "--no-edit", "HEAD")
+
+	cases := []struct {
+		Weight   Weight
+		Net      bool
+		Expected map[string]int64
+	}{
+		// Reverting george's change brings back a line under bob's name
+		{WeightBlame, false, map[string]int64{"abe@git-reviewer.com": 2, "bob@git-reviewer.com": 1}},
+		{WeightBlame, true, map[string]int64{"abe@git-reviewer.com": 2}},
+		{WeightCommits, false, map[string]int64{
+			"abe@git-reviewer.com": 1, "george@git-reviewer.com": 1, "bob@git-reviewer.com": 1,
+		}},
+		{WeightCommits, true, map[string]int64{"abe@git-reviewer.com": 1}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Weight = c.Weight
+		r.NetChangesOnly = c.Net
+
+		stats, err := r.FindReviewerStats([]string{"a.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Errorf("Got %d reviewers with weight %d and net %v, expected %d\n",
+				len(stats), c.Weight, c.Net, len(c.Expected))
+		}
+		for _, stat := range stats {
+			if expected := c.Expected[stat.Reviewer]; stat.Count != expected {
+				t.Errorf("Got %d for %s with weight %d and net %v, expected %d\n",
+					stat.Count, stat.Reviewer, c.Weight, c.Net, expected)
+			}
+		}
+	}
+}
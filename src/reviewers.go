@@ -8,14 +8,17 @@ package gitreviewers
 import (
 	"bufio"
 	"bytes"
-	"container/heap"
+	"context"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
-	"os/exec"
 	"os/user"
+	"path"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
-	"sync"
 	"text/tabwriter"
 	"time"
 
@@ -27,37 +30,362 @@ import (
 
 // ContributionCounter represents a repository and options describing how to
 // count changes and attribute them to collaborators to determine experience.
+//
+// The repository is Repo if given, otherwise the one at WorkDir, otherwise the
+// one in the current directory. Git commands run in WorkDir when it is set.
 type ContributionCounter struct {
 	Repo              *gogit.Repository
+	WorkDir           string
 	ShowFiles         bool
+	ShowRank          bool
 	Verbose           bool
 	Since             string
 	IgnoredExtensions []string
 	OnlyExtensions    []string
-	IgnoredPaths      []string
-	OnlyPaths         []string
 	Mailmap           mailmap
+
+	// IgnoreMailmap leaves the repository's own .mailmap out of the Mailmap,
+	// which otherwise always has it. Git applies it to blame output itself
+	// regardless, so this only changes counts by commit or through
+	// GoGitBackend. See addRepoMailmap.
+	IgnoreMailmap bool
+
+	// IgnoredPaths and OnlyPaths exclude or limit the changed files by path.
+	// Plain entries match paths they prefix, so "src" covers both "src/a.go"
+	// and "src.go". Entries with "*", "?", or "[" are globs matched segment
+	// by segment as in CODEOWNERS: "**" stands for any number of
+	// directories, globs without a slash match a name at any depth, and a
+	// glob covers everything under the directories it matches, except that
+	// one ending in "/*" only covers what is directly in a directory. So
+	// "internal/**", "**/testdata/", "cmd/*/", and "*_test.go" all work.
+	IgnoredPaths []string
+	OnlyPaths    []string
+
+	// OnlyLanguages limits the changed files to those in these languages,
+	// such as "Go" or "C++", as told by their extensions and, for headers
+	// and scripts, their contents. See detectLanguage.
+	OnlyLanguages []string
+
+	// Log is where Verbose output, including every git command run, is
+	// written. Defaults to os.Stderr.
+	Log io.Writer
+
+	// Handles looks up reviewers' GitHub handles where those are needed,
+	// such as to compare them with CODEOWNERS.
+	Handles HandleResolver
+
+	// CriticalPaths are path prefixes, like security/, whose changes need a
+	// reviewer who knows them. Finding reviewers fails with
+	// ErrCriticalPathUncovered when no candidate has worked on a changed
+	// file under one of them.
+	CriticalPaths []string
+
+	// NoScorePaths are glob patterns for changed files that FindFiles still
+	// lists but that don't count toward anyone's experience, such as
+	// lockfiles. See scoredPaths.
+	NoScorePaths []string
+
+	// SkipToolChurn leaves out changed files whose commits since Since are
+	// mostly by formatters and other bots, recognized by ToolAuthors: glob
+	// patterns for author names or emails, defaulting to DefaultToolAuthors.
+	SkipToolChurn bool
+	ToolAuthors   []string
+
+	// Until, like Since, is a "YYYY-MM-DD" bound on which commits count as
+	// experience; commits after that day are ignored. Empty means no bound.
+	Until string
+
+	// TimeZone, when set, is where the Since and Until days start and end,
+	// and commits count as made on the day it was there, so the window is the
+	// same whatever the host's time zone. Otherwise git starts Since at
+	// midnight on the host, and commits are dated in their authors' zones.
+	TimeZone *time.Location
+
+	// BaseBranch is the revision changes are compared against. Despite the
+	// name it may be anything git can resolve to a commit, such as a release
+	// tag. Defaults to the branch origin/HEAD points at, such as origin/main,
+	// or to "master" when there is no such remote.
+	BaseBranch string
+
+	// OnUnrelatedBase, when set, is called by FindFiles with the base when
+	// it isn't an ancestor of HEAD (see BaseIsAncestor), so callers can warn
+	// that the changed files likely include more than the branch's own.
+	OnUnrelatedBase func(base string)
+
+	// ExcludeSelf removes the person running the analysis from the suggested
+	// reviewers. Their identity is read from SelfIdentity when set, otherwise
+	// from `git config user.email`, and `git config user.name` also matches
+	// the names they commit under.
+	ExcludeSelf  bool
+	SelfIdentity string
+
+	// MergeEmailPrefixes counts people who commit under the same name from
+	// addresses like jane@corp.com and jane.doe@corp.com as one reviewer, as
+	// if the mailmap said so. See emailAlias for what counts as the same
+	// person.
+	MergeEmailPrefixes bool
+
+	// ExcludedReviewers removes anyone whose email or most recent name
+	// contains one of these, ignoring case, from the suggested reviewers.
+	ExcludedReviewers []string
+
+	// Bots are never suggested unless IncludeBots is set. They are recognized
+	// by IgnoredAuthorPatterns: regular expressions matched against their
+	// email or most recent name, ignoring case, and defaulting to
+	// DefaultIgnoredAuthorPatterns.
+	IncludeBots           bool
+	IgnoredAuthorPatterns []string
+
+	// MaxReviewers is how many reviewers to suggest. Zero means 3. With
+	// StrictMaxReviewers, finding fewer qualified reviewers than that is an
+	// ErrInsufficientReviewers, though the ones found are still returned.
+	MaxReviewers       int
+	StrictMaxReviewers bool
+
+	// MinCommits drops candidates whose Count across the changed files is
+	// below it, so people who only passed through don't crowd the suggestions.
+	// The Count is in commits with WeightCommits and lines otherwise.
+	MinCommits int
+
+	// MinDistinctReviewers guarantees at least this many different people are
+	// suggested when that many have worked on the changed files, even if they
+	// rank below the usual cutoff.
+	MinDistinctReviewers int
+
+	// PreferFastReviewers boosts reviewers who historically respond quickly to
+	// review requests, as reported by LatencyProvider.
+	PreferFastReviewers bool
+	LatencyProvider     LatencyProvider
+
+	// PreferWorkingHours boosts reviewers who are likely to be working at
+	// Now, judging by when they usually commit (see CommitHabits), for teams
+	// spread across timezones. Now defaults to the current time.
+	PreferWorkingHours bool
+	Now                time.Time
+	habits             map[string]CommitHabit
+
+	// Eligibility filters out collaborators who may not be asked for a
+	// review. Everyone is eligible when it is nil. Its answers are only
+	// remembered for the length of a run.
+	Eligibility EligibilityChecker
+
+	// Concurrency caps how many files are blamed, each by its own git
+	// process, at once. Defaults to runtime.NumCPU().
+	Concurrency int
+
+	// Backend reads the history experience is drawn from, defaulting to
+	// ShellBackend.
+	Backend Backend
+
+	// StateStore, when set, keeps what FindReviewerStats finds for the
+	// commits at the base and HEAD, and answers from it while neither moves
+	// and the window and options stay the same.
+	StateStore StateStore
+
+	// Weight chooses what counts as experience with a file: owned lines by
+	// default, or commits. With SquashConsecutive, consecutive commits to a
+	// file by the same author count once.
+	Weight            Weight
+	SquashConsecutive bool
+
+	// DetectCopies credits lines moved or copied from other files changed in
+	// the same commit to their original authors when counting owned lines.
+	// Lines moved within a file always are.
+	DetectCopies bool
+
+	// ExtraLogArgs are passed through to the git log that reads commit
+	// history, such as --all or --first-parent. Each element is one argument.
+	// Options git-reviewer sets itself, like --format, are rejected.
+	ExtraLogArgs []string
+
+	// Aggregation chooses how experience with each file combines into a
+	// reviewer's score. Defaults to AggregateSum.
+	Aggregation Aggregation
+
+	// NetChangesOnly gives no credit for reverted work: a revert and the
+	// commit it undoes are both left out, found by the "This reverts commit"
+	// line git revert writes.
+	NetChangesOnly bool
+
+	// IgnoreImportCommit gives no credit for the commit that starts the
+	// history, which in repositories imported from another VCS is often one
+	// person adding everything at once. See importCommits.
+	IgnoreImportCommit bool
+
+	// ExcludeOffHours gives no credit for commits made outside WorkingHours,
+	// such as late-night emergency fixes that say little about ownership.
+	ExcludeOffHours bool
+	WorkingHours    WorkingHours
+
+	// DependencyOrder lists the groups from ReviewSequence with the
+	// directories others depend on first, so foundational code is reviewed
+	// before what builds on it. DirDependencies maps each directory to the
+	// ones it depends on; without it, they are found from the imports of
+	// the Go files at HEAD.
+	DependencyOrder bool
+	DirDependencies map[string][]string
+
+	// ActivityWeight counts experience with files under active development
+	// for more than experience with dormant ones. See activityWeights.
+	ActivityWeight bool
+
+	// Veto, when set, is asked about every candidate with the paths being
+	// reviewed, and candidates it returns true for are never suggested. The
+	// next best candidates take their places.
+	Veto func(reviewer Stat, paths []string) bool
+
+	// PostProcess, when set, is the last chance to customize suggestions, for
+	// example to re-rank candidates or inject reviewers the history doesn't
+	// know about. It receives every remaining candidate ordered most preferred
+	// first, after all exclusions and filters. Its result is used in the order
+	// returned and only then truncated to the number of reviewers to suggest.
+	PostProcess func(Stats) Stats
+
+	// MaxDiffFiles skips analysis with ErrDiffTooLarge when more files than
+	// this have changed, such as when dependencies are vendored. Zero means
+	// no limit.
+	MaxDiffFiles int
+
+	// ScorePrecision is how many decimal places to show scores with. Zero
+	// means two places; use WholeNumbers for none.
+	ScorePrecision int
+
+	// MaxWidth keeps the lines FindReviewers writes within this many
+	// characters on a terminal by cutting long reviewers short with an
+	// ellipsis, so their scores stay visible and aligned. Zero means no limit.
+	MaxWidth int
+
+	// RandSeed seeds any randomized choices, such as breaking ties between
+	// equally experienced reviewers, so output is stable for a given seed.
+	RandSeed int64
+
+	// CommandPrefix is a wrapper to run every external git command through,
+	// such as []string{"nice", "-n", "10"}. Each element is one argument;
+	// nothing is split by a shell.
+	CommandPrefix []string
+
+	// RateLimit paces external git commands to at most this many a second,
+	// even when several files are blamed at once, so bursts of processes
+	// don't trip resource limits on shared machines. Zero means no limit.
+	RateLimit float64
+	limiter   *rateLimiter
+
+	// metrics is what the current or most recent run has done (see Metrics).
+	metrics *runMetrics
+
+	// ctx is the context of the current run, if it was given one.
+	ctx context.Context
 }
 
 // Stat contains information about a collaborator and the total "experience"
 // in a branch as determined by the percentage of lines owned out of the total
-// number of lines of code in a changed file.
+// number of lines of code in a changed file (or of commits, see Weight).
 type Stat struct {
-	Reviewer   string
-	Percentage float64
+	Reviewer   string  `json:"reviewer"`
+	Percentage float64 `json:"percentage"`
+
+	// Name is what the reviewer, identified by their email in Reviewer, most
+	// recently committed under. It is empty when that isn't known.
+	Name string `json:"name,omitempty"`
+
+	// Count is the number of lines owned (or commits made, or lines changed),
+	// and LastCommit the date ("YYYY-MM-DD") of the most recent of them. Files
+	// is how many of the changed files they were counted in.
+	Count      int64  `json:"count"`
+	LastCommit string `json:"lastCommit"`
+	Files      int    `json:"files"`
+
+	// Rank is the Stat's place among the suggestions from FindReviewerStats,
+	// starting at 1. Reviewers with the same score share a rank and the ranks
+	// after them skip ahead, so a tie for first is followed by third.
+	Rank int `json:"rank"`
+
+	// Reasons are codes for why the Stat was suggested, such as
+	// ReasonCodeOwner. See the Reason constants for the full set.
+	Reasons []string `json:"reasons,omitempty"`
+
+	// Source is where FindReviewersExplained found the suggestion:
+	// SourceCodeOwner, SourceAuthor, or SourceHistory. It is empty elsewhere.
+	Source string `json:"source,omitempty"`
+
+	// precision is the ScorePrecision of the counter that suggested the
+	// Stat, for String.
+	precision int
 }
 
-// String shows Stat information in a format suitable for shell reporting.
+// String shows Stat information in a format suitable for shell reporting,
+// with the score at the ScorePrecision of the counter that suggested it.
 func (cs *Stat) String() string {
-	return fmt.Sprintf("  %.2f%%\t%s", cs.Percentage*100.0, cs.Reviewer)
+	who := cs.Reviewer
+	if len(cs.Name) > 0 {
+		who = cs.Name + " <" + cs.Reviewer + ">"
+	}
+
+	s := "  " + formatScore(cs.Percentage, resolvePrecision(cs.precision)) + "\t" + who
+	if cs.Files == 1 {
+		s += " (1 file)"
+	} else if cs.Files > 1 {
+		s += fmt.Sprintf(" (%d files)", cs.Files)
+	}
+
+	return s
+}
+
+// defaultScorePrecision is how many decimal places scores are shown with
+// unless ScorePrecision says otherwise.
+const defaultScorePrecision = 2
+
+// WholeNumbers can be used as a ScorePrecision to show scores without any
+// decimal places, since zero selects the default precision.
+const WholeNumbers = -1
+
+// formatScore shows a share between 0 and 1 as a percentage rounded to
+// precision decimal places.
+func formatScore(share float64, precision int) string {
+	return strconv.FormatFloat(share*100.0, 'f', precision, 64) + "%"
+}
+
+// scorePrecision resolves the configured ScorePrecision.
+func (r *ContributionCounter) scorePrecision() int {
+	return resolvePrecision(r.ScorePrecision)
+}
+
+// resolvePrecision is how many decimal places a ScorePrecision of p shows.
+func resolvePrecision(p int) int {
+	switch {
+	case p < 0:
+		return 0
+	case p == 0:
+		return defaultScorePrecision
+	}
+
+	return p
 }
 
 // Stats is a collection of all the collaboration statistics obtained across
-// changes in a repository. By defining our own slice type, we are able to
-// add methods to implement the Heap interface, which we use to determine
-// collaborators with the most experience without sorting the entire list.
+// changes in a repository. It implements the Heap interface, ordered by
+// experience, for clients that want to find the most experienced
+// collaborators without sorting the entire list.
 type Stats []*Stat
 
+// RankedPair is a reviewer and their count, for consumers that want plain
+// ordered pairs.
+type RankedPair struct {
+	Reviewer string `json:"reviewer"`
+	Count    int64  `json:"count"`
+}
+
+// RankedPairs lists the Stats as reviewer and count pairs in the same order.
+// Stats from FindReviewerStats are in rank order, most experienced first.
+func (s Stats) RankedPairs() []RankedPair {
+	pairs := make([]RankedPair, len(s))
+	for i, stat := range s {
+		pairs[i] = RankedPair{Reviewer: stat.Reviewer, Count: stat.Count}
+	}
+
+	return pairs
+}
+
 // Len returns the number of Stat objects.
 func (s Stats) Len() int {
 	return len(s)
@@ -119,6 +447,10 @@ var defaultIgnoreExt = []string{
 // It will skip over any files it is unable to open without error. If none are
 // parsed, it will result in an empty mailmap.
 func (r *ContributionCounter) BuildMailmap(paths ...string) {
+	if r == nil {
+		return
+	}
+
 	// If no paths specified, attempt by guessing that it will be in the user's
 	// home path.
 	if len(paths) == 0 {
@@ -148,58 +480,46 @@ func guessUserMailmap() (string, error) {
 	}
 }
 
-// BranchBehind determines if the current branch is "behind"
-// by comparing the current branch HEAD reference to that of the local ref of
-// the master branch.
+// BranchBehind determines if the current branch is "behind" the base (see
+// BaseBranch): whether the base has commits the branch doesn't, which is the
+// case unless the base is an ancestor of HEAD (see BaseIsAncestor). A branch
+// that has diverged, with commits of its own and missing some of the base's,
+// is behind too. Commit dates don't matter, since a branch can be missing
+// work from the base even when its tip is newer.
+//
+// A branch with no history in common with the base, as when the base is
+// unrelated or a shallow clone doesn't reach where they meet, can't be merged
+// up, so it isn't behind; FindFiles warns about it through OnUnrelatedBase.
 func (r *ContributionCounter) BranchBehind() (bool, error) {
-	var (
-		behind bool
-		h      *plumbing.Reference
-		hObj   *object.Commit
-		m      *plumbing.Reference
-		mObj   *object.Commit
-		rg     runGuard
-	)
+	defer r.startRun()()
 
-	rg.maybeRunMany(
-		func() {
-			m, rg.err = r.Repo.Reference(plumbing.Master, true)
-			rg.msg = "issue opening master reference"
-		},
-		func() {
-			h, rg.err = r.Repo.Reference(plumbing.HEAD, true)
-			rg.msg = "issue opening HEAD reference"
-		},
-		func() {
-			mObj, rg.err = r.Repo.CommitObject(m.Hash())
-			rg.msg = "issue opening master commit"
-		},
-		func() {
-			hObj, rg.err = r.Repo.CommitObject(h.Hash())
-			rg.msg = "issue opening HEAD commit"
-		},
-		func() {
-			behind = hObj.Committer.When.Before(mObj.Committer.When)
-			rg.msg = "issue comparing commit dates"
-		},
-	)
-
-	if rg.err != nil && rg.msg != "" && r.Verbose {
-		fmt.Printf("Error comparing branches: '%s'\n", rg.msg)
+	ancestor, err := r.BaseIsAncestor()
+	if err != nil || ancestor {
+		return false, err
 	}
 
-	return behind, rg.err
+	return r.hasMergeBase()
 }
 
 // FindFiles returns a list of paths to files that have been changed
-// in this branch with respect to "master".
+// in this branch with respect to the base (see BaseBranch).
+//
+// The trees at the base and HEAD are compared directly, like "git diff base
+// HEAD", rather than from their merge base. That still works when there is no
+// merge base, as in shallow clones, though the diff then includes whatever
+// changed on the base too; OnUnrelatedBase is called to warn about it.
 func (r *ContributionCounter) FindFiles() ([]string, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
 	var (
 		changes object.Changes
 		h       *plumbing.Reference
 		hc      *object.Commit
 		ht      *object.Tree
-		m       *plumbing.Reference
 		mc      *object.Commit
 		mt      *object.Tree
 		paths   []string
@@ -210,16 +530,12 @@ func (r *ContributionCounter) FindFiles() ([]string, error) {
 
 	rg.maybeRunMany(
 		func() {
-			m, rg.err = r.Repo.Reference(plumbing.Master, true)
-			rg.msg = "issue opening master ref"
-		},
-		func() {
-			mc, rg.err = r.Repo.CommitObject(m.Hash())
-			rg.msg = "issue opening master commit"
+			mc, rg.err = r.baseCommit()
+			rg.msg = "issue opening base commit"
 		},
 		func() {
 			mt, rg.err = mc.Tree()
-			rg.msg = "issue opening tree at master"
+			rg.msg = "issue opening tree at base"
 		},
 		func() {
 			h, rg.err = r.Repo.Reference(plumbing.HEAD, true)
@@ -235,35 +551,233 @@ func (r *ContributionCounter) FindFiles() ([]string, error) {
 		},
 		func() {
 			changes, rg.err = object.DiffTree(mt, ht)
-			rg.msg = "issue diffing master and head trees"
+			rg.msg = "issue diffing base and head trees"
 		},
 		func() {
-			for _, ch := range changes {
-				// Only keep the names that existed in "master" before the change.
-				// Otherwise we'll try to 'blame' files that don't exist in master if a
-				// file was created or renamed in the development branch.
-				n := ch.From.Name
-				if len(n) > 0 && considerExt(n, r) && considerPath(n, r) {
-					set[n] = true
-				}
-			}
+			r.keepChanges(set, changes)
 		},
 	)
 
-	if rg.err != nil && rg.msg != "" && r.Verbose {
-		fmt.Printf("Error finding diff files: '%s'\n", rg.msg)
+	if rg.err != nil && rg.msg != "" {
+		r.verbosef("Error finding diff files: '%s'\n", rg.msg)
 	}
 
 	for path := range set {
 		paths = append(paths, path)
 	}
 
+	if rg.err == nil && r.OnUnrelatedBase != nil {
+		ok, err := r.BaseIsAncestor()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			r.OnUnrelatedBase(r.base())
+		}
+	}
+
 	return paths, rg.err
 }
 
+// FindCommitFiles returns the paths changed across a set of commits, such as a
+// patch series or a batch of cherry-picks, with respect to each commit's first
+// parent. Paths touched by more than one commit are only listed once.
+func (r *ContributionCounter) FindCommitFiles(shas []string) ([]string, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	set := make(map[string]bool)
+
+	for _, sha := range shas {
+		var (
+			c       *object.Commit
+			changes object.Changes
+			ct      *object.Tree
+			h       *plumbing.Hash
+			pt      *object.Tree
+			rg      runGuard
+		)
+
+		rg.maybeRunMany(
+			func() {
+				h, rg.err = r.Repo.ResolveRevision(plumbing.Revision(sha))
+				rg.msg = "issue resolving commit " + sha
+			},
+			func() {
+				c, rg.err = r.Repo.CommitObject(*h)
+				rg.msg = "issue opening commit " + sha
+			},
+			func() {
+				ct, rg.err = c.Tree()
+				rg.msg = "issue opening tree at " + sha
+			},
+			func() {
+				// A root commit has nothing to diff against, so every file it
+				// introduces is skipped just like a newly created file would be.
+				if c.NumParents() == 0 {
+					return
+				}
+
+				var p *object.Commit
+				if p, rg.err = c.Parent(0); rg.err == nil {
+					pt, rg.err = p.Tree()
+				}
+				rg.msg = "issue opening parent tree of " + sha
+			},
+			func() {
+				if pt != nil {
+					changes, rg.err = object.DiffTree(pt, ct)
+					rg.msg = "issue diffing " + sha + " against its parent"
+				}
+			},
+			func() {
+				r.keepChanges(set, changes)
+			},
+		)
+
+		if rg.err != nil {
+			if rg.msg != "" {
+				r.verbosef("Error finding commit files: '%s'\n", rg.msg)
+			}
+
+			return nil, rg.err
+		}
+	}
+
+	for path := range set {
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// FindReviewersForCommits returns the top reviewers across all the files
+// changed by a set of commits. See FindCommitFiles.
+func (r *ContributionCounter) FindReviewersForCommits(shas []string) (string, error) {
+	defer r.startRun()()
+
+	files, err := r.FindCommitFiles(shas)
+	if err != nil {
+		return "", err
+	}
+
+	return r.FindReviewers(files)
+}
+
+// FindReviewersForMerge returns the top reviewers for what a merge commit
+// brought in: the files it changed with respect to its first parent, the
+// branch that was merged into. For an octopus merge that covers every merged
+// branch at once. Commits with a single parent are rejected.
+func (r *ContributionCounter) FindReviewersForMerge(sha string) (string, error) {
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return "", err
+	}
+
+	h, err := r.Repo.ResolveRevision(plumbing.Revision(sha))
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve commit %s", sha)
+	}
+	c, err := r.Repo.CommitObject(*h)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to open commit %s", sha)
+	}
+	if c.NumParents() < 2 {
+		return "", errors.Errorf("%s is not a merge commit", sha)
+	}
+
+	return r.FindReviewersForCommits([]string{sha})
+}
+
+// FindReviewersForLastCommits returns the top reviewers for the files changed
+// in the last n commits on the current branch, from HEAD~n to HEAD. A merge
+// is one of them, covering what it brought in. Asking for more commits than
+// the branch has considers its whole history.
+func (r *ContributionCounter) FindReviewersForLastCommits(n int) (string, error) {
+	defer r.startRun()()
+
+	shas, err := r.lastCommits(n)
+	if err != nil {
+		return "", err
+	}
+
+	return r.FindReviewersForCommits(shas)
+}
+
+// lastCommits lists the hashes of up to n of the most recent commits on HEAD.
+// Only first parents are followed, so a merge counts as one commit rather
+// than bringing in the history of the branch it merged.
+func (r *ContributionCounter) lastCommits(n int) ([]string, error) {
+	if n < 1 {
+		return nil, errors.Errorf("need at least one commit, got %d", n)
+	}
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	if !r.runsGit() {
+		head, err := r.headCommit()
+		if err != nil {
+			return nil, err
+		}
+
+		commits, err := firstParents(head, n)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to list recent commits")
+		}
+
+		shas := make([]string, len(commits))
+		for i, c := range commits {
+			shas[i] = c.Hash.String()
+		}
+		return shas, nil
+	}
+
+	out, err := r.git("rev-list", "--first-parent", "--max-count="+strconv.Itoa(n), "HEAD")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list recent commits")
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
+// keepChanges adds the paths from a tree diff that pass the extension and path
+// filters to set.
+func (r *ContributionCounter) keepChanges(set map[string]bool, changes object.Changes) {
+	for _, ch := range changes {
+		// Only keep the names that existed in the base before the change.
+		// Otherwise we'll try to 'blame' files that don't exist in the base if a
+		// file was created or renamed in the development branch.
+		n := ch.From.Name
+		if len(n) == 0 || !considerExt(n, r) || !considerPath(n, r) {
+			continue
+		}
+
+		from := ch.From
+		contents := func() (string, error) {
+			f, err := from.Tree.TreeEntryFile(&from.TreeEntry)
+			if err != nil {
+				return "", err
+			}
+			return f.Contents()
+		}
+		if considerLanguage(n, contents, r) {
+			set[n] = true
+		}
+	}
+}
+
 // considerExt determines whether a path should be used to calculate the final
 // collaborators score based on the inclusion or absence of its extension in the
-// list of paths to exlusively include or exclude, respectively.
+// list of paths to exlusively include or exclude, respectively. Extensions
+// match regardless of case, so IMAGE.SVG is ignored like image.svg, and only
+// after a dot, so "go" matches main.go but not cargo.
 func considerExt(path string, opts *ContributionCounter) bool {
 	ignExt := []string{}
 	ignExt = append(ignExt, defaultIgnoreExt...)
@@ -277,14 +791,14 @@ func considerExt(path string, opts *ContributionCounter) bool {
 
 	if lAllow > 0 {
 		for _, ext := range opts.OnlyExtensions {
-			if strings.HasSuffix(path, ext) {
+			if hasExt(path, ext) {
 				return true
 			}
 		}
 	} else if lIgnore > 0 {
 		passes := true
 		for _, ext := range ignExt {
-			passes = passes && !strings.HasSuffix(path, ext)
+			passes = passes && !hasExt(path, ext)
 		}
 
 		return passes
@@ -293,12 +807,52 @@ func considerExt(path string, opts *ContributionCounter) bool {
 	return false
 }
 
+// hasExt reports whether path ends with the extension ext, ignoring case.
+// The dot before ext is optional, so "go" and ".go" are the same, and an
+// empty ext matches nothing.
+func hasExt(path, ext string) bool {
+	ext = strings.TrimPrefix(ext, ".")
+	if len(ext) == 0 {
+		return false
+	}
+
+	return strings.HasSuffix(strings.ToLower(path), "."+strings.ToLower(ext))
+}
+
+// scoredPaths leaves out the paths matching NoScorePaths. A pattern without a
+// slash may match just the file name, wherever it is.
+func (r *ContributionCounter) scoredPaths(paths []string) []string {
+	if len(r.NoScorePaths) == 0 {
+		return paths
+	}
+
+	var scored []string
+	for _, p := range paths {
+		skip := false
+		for _, pattern := range r.NoScorePaths {
+			name := p
+			if !strings.Contains(pattern, "/") {
+				name = path.Base(p)
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				skip = true
+				break
+			}
+		}
+
+		if !skip {
+			scored = append(scored, p)
+		}
+	}
+
+	return scored
+}
+
 // considerPath determines whether a path should be used to calculate the final
 // collaborators score based on its inclusion or absence in the list of paths to
 // exlusively include or exclude, respectively.
 func considerPath(path string, opts *ContributionCounter) bool {
 	lAllow, lIgnore := len(opts.OnlyPaths), len(opts.IgnoredPaths)
-	pLen := len(path)
 
 	if lAllow == 0 && lIgnore == 0 {
 		return true
@@ -306,14 +860,14 @@ func considerPath(path string, opts *ContributionCounter) bool {
 
 	if lAllow > 0 {
 		for _, prefix := range opts.OnlyPaths {
-			if len(strings.TrimPrefix(path, prefix)) < pLen {
+			if matchesPath(path, prefix) {
 				return true
 			}
 		}
 	} else if lIgnore > 0 {
 		passes := true
 		for _, prefix := range opts.IgnoredPaths {
-			passes = passes && len(strings.TrimPrefix(path, prefix)) == pLen
+			passes = passes && !matchesPath(path, prefix)
 		}
 
 		return passes
@@ -321,8 +875,21 @@ func considerPath(path string, opts *ContributionCounter) bool {
 	return false
 }
 
-// FindReviewers returns up to 3 of the top reviewers information as determined
-// by percentage of owned lines of all lines in changed file.
+// matchesPath reports whether an IgnoredPaths or OnlyPaths entry covers a
+// path, as a glob when it has wildcards and as a prefix otherwise. Paths from
+// git always use "/", so globs behave the same on every platform.
+func matchesPath(path, entry string) bool {
+	if strings.ContainsAny(entry, "*?[") {
+		return parsePattern(entry).matches(path)
+	}
+
+	return len(strings.TrimPrefix(path, entry)) < len(path)
+}
+
+// FindReviewers returns up to MaxReviewers of the top reviewers information as
+// determined by percentage of owned lines of all lines in changed file. With
+// ShowRank, each is numbered by its Rank. It is empty when everyone found was
+// excluded.
 //
 // NOTE: This previously use go-git to create a blame object for each file in
 // 'paths', but the performance and concurrency errors proved to make this
@@ -333,175 +900,802 @@ func considerPath(path string, opts *ContributionCounter) bool {
 // - https://github.com/src-d/go-git/issues/457
 // - https://github.com/src-d/go-git/issues/458
 func (r *ContributionCounter) FindReviewers(paths []string) (string, error) {
-	var final Stats
+	defer r.startRun()()
 
-	if len(r.Since) == 0 {
-		// Calculate 6 months ago from today's date and set the 'since' argument
-		r.Since = time.Now().AddDate(0, -6, 0).Format("2006-01-02")
+	topN, err := r.FindReviewerStats(paths)
+	if err != nil || len(topN) == 0 {
+		return "", err
 	}
 
-	// Example shell call:
-	// git blame -ce 9901bf79f808a8339b9820c08e209f5ec9649bda src/reviewers.go
-	linesByCommitter, totalLines, err := r.generateCounts(paths)
-	if err != nil {
-		return "", err
+	scores := make([]string, len(topN))
+	for i, stat := range topN {
+		scores[i] = formatScore(stat.Percentage, r.scorePrecision())
 	}
+	width := r.reviewerWidth(scores)
 
-	for author, lines := range linesByCommitter {
-		// Calculate percent of lines touched in-place
-		lines := lines
-		linesByCommitter[author] = lines / float64(totalLines)
+	var buffer bytes.Buffer
+	tw := tabwriter.NewWriter(&buffer, 0, tabStop, 1, '\t', 0)
+
+	header, rule := truncate("Reviewer", width), truncate("--------", width)
+	if r.ShowRank {
+		fmt.Fprintf(tw, "Rank\t%s\tExperience\n", header)
+		fmt.Fprintf(tw, "----\t%s\t----------\n", rule)
+	} else {
+		fmt.Fprintf(tw, "%s\tExperience\n", header)
+		fmt.Fprintf(tw, "%s\t----------\n", rule)
+	}
+
+	for i := range topN {
+		if r.ShowRank {
+			fmt.Fprintf(tw, "#%d\t", topN[i].Rank)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", truncate(topN[i].Reviewer, width), scores[i])
+	}
+	tw.Flush()
+
+	return buffer.String(), nil
+}
+
+// tabStop is how far apart the tab stops FindReviewers aligns columns to are,
+// as terminals usually have them.
+const tabStop = 8
+
+// reviewerWidth is how many characters of each reviewer FindReviewers can
+// show and keep within MaxWidth, or 0 for no limit. Columns are padded with
+// tabs, so the reviewer column ends at the first tab stop past its widest
+// cell, and only the scores come after it. Ranks fit before the first stop.
+func (r *ContributionCounter) reviewerWidth(scores []string) int {
+	if r.MaxWidth <= 0 {
+		return 0
 	}
 
-	final = make(Stats, len(linesByCommitter))
-	idx := 0
-	for c, p := range linesByCommitter {
-		final[idx] = &Stat{c, p}
-		idx++
+	last := len("Experience")
+	for _, s := range scores {
+		if len(s) > last {
+			last = len(s)
+		}
 	}
 
-	maxStats := 3
-	if l := len(final); l < maxStats {
-		maxStats = l
+	avail := r.MaxWidth - last
+	if r.ShowRank {
+		avail -= tabStop
 	}
-	topN := chooseTopN(maxStats, final)
 
-	var buffer bytes.Buffer
-	tw := tabwriter.NewWriter(&buffer, 0, 8, 1, '\t', 0)
+	// At least a character and the ellipsis, however narrow it gets
+	if width := avail/tabStop*tabStop - 1; width > 2 {
+		return width
+	}
+	return 2
+}
+
+// truncate cuts s down to width characters, ending in an ellipsis when
+// anything was cut. A width of 0 leaves s alone.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+
+	return string(runes[:width-1]) + "…"
+}
+
+// FindReviewersFromReader finds reviewers for a list of changed files supplied
+// by another tool, one path per line, rather than diffing branches. This lets
+// callers that already know what changed get suggestions from any repository
+// by pointing WorkDir at it. Blank lines are skipped and the usual extension
+// and path filters apply.
+func (r *ContributionCounter) FindReviewersFromReader(rdr io.Reader) (string, error) {
+	defer r.startRun()()
+
+	var paths []string
+	set := make(map[string]bool)
+
+	scn := bufio.NewScanner(rdr)
+	for scn.Scan() {
+		p := strings.TrimSpace(scn.Text())
+		if len(p) > 0 && !set[p] && considerExt(p, r) && considerPath(p, r) {
+			set[p] = true
+			paths = append(paths, p)
+		}
+	}
+	if err := scn.Err(); err != nil {
+		return "", errors.Wrap(err, "unable to read changed files")
+	}
+
+	return r.FindReviewers(paths)
+}
+
+// FindReviewerStats returns the top reviewers for the changed paths, most
+// experienced first, as the Stats that FindReviewers formats for display.
+// When ExcludeSelf, ExcludedReviewers, leaving out bots, or MinCommits leave
+// no one, there are no Stats and no error.
+func (r *ContributionCounter) FindReviewerStats(paths []string) (Stats, error) {
+	defer r.startRun()()
+
+	var key string
+	if r != nil && r.StateStore != nil {
+		if err := r.prepare(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if key, err = r.stateKey(paths); err != nil {
+			return nil, err
+		}
+
+		saved, ok, err := r.StateStore.Load(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load state")
+		} else if ok {
+			return saved, nil
+		}
+	}
+
+	final, _, err := r.candidates(paths)
+	if _, ok := err.(allExcludedErr); ok {
+		return Stats{}, r.saveState(key, Stats{})
+	} else if err != nil {
+		return nil, err
+	}
+
+	top, err := r.selectTop(final)
+	if _, ok := err.(ErrInsufficientReviewers); err != nil && !ok {
+		return nil, err
+	}
+	if describeErr := r.describe(top, paths); describeErr != nil {
+		return nil, describeErr
+	}
+
+	// Short lists stay unsaved so StrictMaxReviewers still reports them.
+	if err == nil {
+		err = r.saveState(key, top)
+	}
+
+	return top, err
+}
+
+// saveState keeps s in the StateStore under key, if there is a store.
+func (r *ContributionCounter) saveState(key string, s Stats) error {
+	if r.StateStore == nil {
+		return nil
+	}
+
+	return errors.Wrap(r.StateStore.Save(key, s), "unable to save state")
+}
+
+// selectTop narrows the scored candidates down to the reviewers to suggest.
+func (r *ContributionCounter) selectTop(final Stats) (Stats, error) {
+	var topN Stats
+
+	if r.PreferWorkingHours {
+		habits, err := r.CommitHabits()
+		if err != nil {
+			return nil, err
+		}
+		r.habits = habits
+	}
+
+	if r.PostProcess != nil {
+		// The hook sees every candidate in rank order, and its order stands.
+		topN = r.PostProcess(r.rank(len(final), final))
+		if limit := r.reviewerLimit(); len(topN) > limit {
+			topN = topN[:limit]
+		}
+	} else {
+		maxStats := r.reviewerLimit()
+		if l := len(final); l < maxStats {
+			maxStats = l
+		}
+		topN = r.rank(maxStats, final)
+	}
 
 	if len(topN) == 0 {
-		return "", noReviewersErr{}
+		return nil, noReviewersErr{}
 	}
+	assignRanks(topN)
 
-	fmt.Fprintln(tw, "Reviewer\tExperience")
-	fmt.Fprintln(tw, "--------\t----------")
+	if limit := r.reviewerLimit(); r.StrictMaxReviewers && len(topN) < limit {
+		return topN, ErrInsufficientReviewers{Found: len(topN), Wanted: limit}
+	}
 
-	for i := range topN {
-		fmt.Fprintf(tw, "%s\t%.2f%%\n", topN[i].Reviewer, topN[i].Percentage*100.0)
+	return topN, nil
+}
+
+// assignRanks numbers Stats in the order given. See Stat.Rank.
+func assignRanks(s Stats) {
+	for i, stat := range s {
+		if i > 0 && stat.Percentage == s[i-1].Percentage {
+			stat.Rank = s[i-1].Rank
+		} else {
+			stat.Rank = i + 1
+		}
 	}
-	tw.Flush()
+}
 
-	return buffer.String(), nil
+// rank chooses the n most preferred candidates, best first.
+func (r *ContributionCounter) rank(n int, final Stats) Stats {
+	if r.PreferFastReviewers && r.LatencyProvider != nil {
+		return r.preferFast(n, final)
+	}
+	if r.PreferWorkingHours {
+		return r.preferAwake(n, final)
+	}
+
+	return chooseTopN(n, final)
 }
 
-func (r *ContributionCounter) generateCounts(paths []string) (map[string]float64, uint16, error) {
+// defaultMaxReviewers is how many reviewers are suggested unless MaxReviewers
+// says otherwise.
+const defaultMaxReviewers = 3
+
+// reviewerLimit determines how many of the top reviewers to suggest.
+func (r *ContributionCounter) reviewerLimit() int {
+	n := defaultMaxReviewers
+	if r.MaxReviewers > 0 {
+		n = r.MaxReviewers
+	}
+	if r.MinDistinctReviewers > n {
+		n = r.MinDistinctReviewers
+	}
+
+	return n
+}
+
+// candidates scores every collaborator on the changed paths by their share of
+// owned lines, after applying any exclusions. The per-file line counts the
+// scores were built from are returned alongside for callers that need them.
+func (r *ContributionCounter) candidates(paths []string) (Stats, contributions, error) {
+	if err := r.prepare(); err != nil {
+		return nil, nil, err
+	}
+
+	if r.MaxDiffFiles > 0 && len(paths) > r.MaxDiffFiles {
+		return nil, nil, ErrDiffTooLarge{Files: len(paths), Limit: r.MaxDiffFiles}
+	}
+	paths = r.scoredPaths(paths)
+	r.count(func(m *Metrics) { m.FilesAnalyzed = len(paths) })
+
+	paths, err := r.skipToolChurn(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byFile, weights, err := r.experience(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	final, err := r.score(paths, byFile, weights)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return final, byFile, nil
+}
+
+// experience attributes the history of each path to collaborators, along with
+// each path's activity weight when ActivityWeight is on.
+func (r *ContributionCounter) experience(paths []string) (contributions, map[string]float64, error) {
 	var (
-		linesByCommitter = make(map[string]float64)
-		m                *plumbing.Reference
-		mc               *object.Commit
-		rg               runGuard
-		totalLines       uint16
-		wg               sync.WaitGroup
+		byFile contributions
+		err    error
 	)
 
-	// Set up tracking for each of these files to be blamed concurrently with
-	// results from each reported on a single channel.
-	wg.Add(len(paths))
-	reporter := make(chan []string)
+	if r.MergeEmailPrefixes {
+		if err := r.mergeEmailPrefixes(); err != nil {
+			return nil, nil, err
+		}
+	}
+	switch r.Weight {
+	case WeightCommits, WeightLines:
+		byFile, err = r.commitCounts(paths)
+	default:
+		// Example shell call:
+		// git blame -cel -M 9901bf79f808a8339b9820c08e209f5ec9649bda src/reviewers.go
+		byFile, err = r.generateCounts(paths)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Get the master commit so we can determine what the experience was *before*
-	// the author got to the file.
-	rg.maybeRunMany(
-		func() {
-			m, rg.err = r.Repo.Reference(plumbing.Master, true)
-			rg.msg = "unable to find ref for master"
-		},
-		func() {
-			mc, rg.err = r.Repo.CommitObject(m.Hash())
-			rg.msg = "unable to find commit for master"
-		},
-		func() {
-			for _, p := range paths {
-				go func(p string) {
-					// A separate run has already indicated a blame error. Skip
-					if rg.err != nil {
-						rg.msg = "Issue running git blame for " + p
-						return
-					}
-
-					err := r.runAndReport(p, m.Hash().String(), reporter)
-					// Report any errors to the rungroup so future goroutines don't
-					// attempt any further processsing.
-					if err != nil {
-						rg.err = err
-					}
-				}(p)
-			}
-		},
+	if r.NetChangesOnly {
+		cancelled, err := r.revertedCommits()
+		if err != nil {
+			return nil, nil, err
+		}
+		dropCommits(byFile, cancelled)
+	}
+
+	if r.IgnoreImportCommit {
+		imports, err := r.importCommits()
+		if err != nil {
+			return nil, nil, err
+		}
+		dropCommits(byFile, imports)
+	}
+
+	if r.ExcludeOffHours {
+		offHours, err := r.offHoursCommits()
+		if err != nil {
+			return nil, nil, err
+		}
+		dropCommits(byFile, offHours)
+	}
+
+	var weights map[string]float64
+	if r.ActivityWeight {
+		if weights, err = r.activityWeights(paths); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return byFile, weights, nil
+}
+
+// score combines the experience with the paths into the candidates to suggest
+// from, in no particular order, with everyone excluded or filtered out
+// already gone. byFile and weights may cover more than paths.
+func (r *ContributionCounter) score(paths []string, byFile contributions, weights map[string]float64) (Stats, error) {
+	var (
+		final    Stats
+		byAuthor = make(map[string]*Stat)
+		total    float64
+		weighted = make(map[string]float64)
+		err      error
+
+		// Per-file shares combined according to Aggregation
+		fileWeights float64
+		shares      = make(map[string]float64)
 	)
 
-	// Bail early from further processing if we couldn't run a git-blame for each
-	// path identified
-	if rg.err != nil {
-		if rg.msg != "" && r.Verbose {
-			fmt.Println("Error blaming changed files:", rg.msg)
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			continue
 		}
+		seen[path] = true
+
+		lines := byFile[path]
+		w := 1.0
+		if weights != nil {
+			w = weights[path]
+		}
+
+		inFile := make(map[string]float64)
+		var size float64
+		for _, line := range lines {
+			stat, ok := byAuthor[line.author]
+			if !ok {
+				stat = &Stat{Reviewer: line.author}
+				byAuthor[line.author] = stat
+				final = append(final, stat)
+			}
 
-		return nil, 0, rg.err
+			if inFile[line.author] == 0 {
+				stat.Files++
+			}
+			n := line.size()
+			inFile[line.author] += float64(n)
+			size += float64(n)
+			stat.Count += n
+			if line.date > stat.LastCommit {
+				stat.LastCommit = line.date
+			}
+			weighted[line.author] += w * float64(n)
+			total += w * float64(n)
+		}
+
+		if len(lines) > 0 {
+			fileWeights += w
+			r.Aggregation.addFile(shares, inFile, size, w)
+		}
+	}
+
+	for _, stat := range final {
+		// Calculate percent of lines touched in-place
+		switch r.Aggregation {
+		case AggregateMax:
+			stat.Percentage = shares[stat.Reviewer]
+		case AggregateMean:
+			stat.Percentage = shares[stat.Reviewer] / fileWeights
+		default:
+			stat.Percentage = weighted[stat.Reviewer] / total
+		}
+	}
+
+	// Map iteration order would otherwise decide which of several equally
+	// experienced reviewers make the cut. Spread those picks around, but
+	// reproducibly for a given RandSeed.
+	sort.Slice(final, func(i, j int) bool {
+		return final[i].Reviewer < final[j].Reviewer
+	})
+	rng := rand.New(rand.NewSource(r.RandSeed))
+	rng.Shuffle(len(final), func(i, j int) {
+		final[i], final[j] = final[j], final[i]
+	})
+
+	if r.ExcludeSelf || len(r.ExcludedReviewers) > 0 || !r.IncludeBots {
+		found := len(final)
+		if final, err = r.exclude(final); err != nil {
+			return nil, err
+		}
+		if found > 0 && len(final) == 0 {
+			return nil, allExcludedErr{}
+		}
+	}
+
+	if r.MinCommits > 0 {
+		found := len(final)
+		final = r.dropDriveBys(final)
+		if found > 0 && len(final) == 0 {
+			return nil, allExcludedErr{}
+		}
+	}
+
+	if final, err = r.filterEligible(final); err != nil {
+		return nil, err
+	}
+
+	if r.Veto != nil {
+		final = r.applyVeto(final, paths)
+	}
+
+	if err := r.checkCriticalPaths(paths, byFile, final); err != nil {
+		return nil, err
+	}
+
+	return final, nil
+}
+
+// dropDriveBys drops the candidates with a Count below MinCommits.
+func (r *ContributionCounter) dropDriveBys(s Stats) Stats {
+	var kept Stats
+	for _, stat := range s {
+		if stat.Count >= int64(r.MinCommits) {
+			kept = append(kept, stat)
+		}
 	}
 
-	// Collect all the git-blame line responses as they come in. This loop will
-	// continue as long as the reporter channel is open. We'll close the channel
-	// when all blame processes report they have finished.
-	go func() {
-		for attributions := range reporter {
-			for _, author := range attributions {
-				linesByCommitter[author]++
-				totalLines++
+	return kept
+}
+
+// applyVeto drops the candidates Veto rejects for the paths.
+func (r *ContributionCounter) applyVeto(s Stats, paths []string) Stats {
+	var kept Stats
+	for _, stat := range s {
+		if !r.Veto(*stat, paths) {
+			kept = append(kept, stat)
+		}
+	}
+
+	return kept
+}
+
+// checkCriticalPaths makes sure every changed path under one of the
+// CriticalPaths has experience from at least one remaining candidate.
+func (r *ContributionCounter) checkCriticalPaths(paths []string, byFile contributions, final Stats) error {
+	if len(r.CriticalPaths) == 0 {
+		return nil
+	}
+
+	remaining := make(map[string]bool, len(final))
+	for _, stat := range final {
+		remaining[stat.Reviewer] = true
+	}
+
+	for _, p := range paths {
+		critical := false
+		for _, prefix := range r.CriticalPaths {
+			critical = critical || strings.HasPrefix(p, prefix)
+		}
+		if !critical {
+			continue
+		}
+
+		covered := false
+		for _, line := range byFile[p] {
+			if remaining[line.author] {
+				covered = true
+				break
 			}
-			wg.Done()
 		}
-	}()
+		if !covered {
+			return ErrCriticalPathUncovered{Path: p}
+		}
+	}
+
+	return nil
+}
 
-	wg.Wait()
-	close(reporter)
+// since resolves the Since boundary, defaulting to defaultSince, or to 6
+// months ago when the history can't be read, as it can't before the counter
+// has a repository. The history is only read once a run, and the default is
+// never written back to Since, so every run works it out afresh.
+func (r *ContributionCounter) since() string {
+	if len(r.Since) > 0 {
+		return r.Since
+	}
+	if r.Repo == nil {
+		return time.Now().AddDate(0, -6, 0).Format("2006-01-02")
+	}
 
-	return linesByCommitter, totalLines, nil
+	return r.perRun("since", func() string {
+		since, err := r.defaultSince(r.base())
+		if err != nil {
+			return time.Now().AddDate(0, -6, 0).Format("2006-01-02")
+		}
+
+		return since
+	})
 }
 
-// runAndReport executes an external call to git to calculate blame statistics
-// for a file at a specific commit (usually "master" or whatever the base branch
-// is) and send extracted statistics to the 'reporter' channel.
-func (r *ContributionCounter) runAndReport(path string, rev string, reporter chan []string) error {
-	out, err := exec.Command("git", "blame", "-ce", rev, path).Output()
+// defaultSinceCommits is how many of the base's most recent commits the
+// default Since boundary reaches back to at least.
+const defaultSinceCommits = 100
+
+// defaultSince picks a Since boundary that suits how active the repository
+// is: 6 months ago, or further back if that's what it takes to cover the last
+// defaultSinceCommits commits on the base (or all of them, if it has fewer).
+// Busy repositories get a window of recent experience, while quiet ones still
+// get enough history to find anyone at all.
+func (r *ContributionCounter) defaultSince(base string) (string, error) {
+	since := time.Now().AddDate(0, -6, 0).Format("2006-01-02")
+
+	dates, err := r.firstParentDates(base)
 	if err != nil {
-		return errors.Wrap(err, "unable to execute external git blame command")
+		return "", err
 	}
 
-	scn := bufio.NewScanner(bytes.NewReader(out))
-	var attributions []string
+	if len(dates) > 0 && dates[len(dates)-1] < since {
+		since = dates[len(dates)-1]
+	}
 
-	for scn.Scan() {
-		if bi, err := parseBlameLine(scn.Bytes()); err == nil {
-			// r.Since is a string, not a date. However, since the format is just
-			// a "YYYY-MM-DD" string, we can rely on ASCII sorting and just compare
-			// the strings to determine if a line change was committed before or after
-			// our boundary
-			if r.Since > string(bi.date) {
-				continue
+	return since, nil
+}
+
+// firstParentDates lists the days of the last defaultSinceCommits commits on
+// the first-parent history of base, newest first. Dates are author dates, as
+// blame reports them.
+func (r *ContributionCounter) firstParentDates(base string) ([]string, error) {
+	if r.runsGit() {
+		out, err := r.git("log", "--first-parent", "--format=%ad", "--date=short",
+			"-n", strconv.Itoa(defaultSinceCommits), base, "--")
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to execute external git log command")
+		}
+
+		return strings.Fields(string(out)), nil
+	}
+
+	h, err := r.backend().Resolve(r, base)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to resolve base '%s'", base)
+	}
+	c, err := r.Repo.CommitObject(h)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := firstParents(c, defaultSinceCommits)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, len(commits))
+	for i, c := range commits {
+		dates[i] = c.Author.When.Format("2006-01-02")
+	}
+
+	return dates, nil
+}
+
+// exclude drops the Stats belonging to the person running the analysis, with
+// ExcludeSelf, to anyone ExcludedReviewers names, and to bots.
+func (r *ContributionCounter) exclude(s Stats) (Stats, error) {
+	bots, err := r.botPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	var self, selfName string
+	if r.ExcludeSelf {
+		email, err := r.selfIdentity()
+		if err != nil {
+			return nil, err
+		}
+		self = reviewerKey(email, r.Mailmap)
+
+		// An explicit SelfIdentity is an email, so only git config says who
+		// we are by name. Not having a name set is fine.
+		if len(r.SelfIdentity) == 0 {
+			if name, err := r.gitConfig("user.name"); err == nil {
+				selfName = name
 			}
+		}
+	}
+
+	if len(selfName) > 0 || len(r.ExcludedReviewers) > 0 || len(bots) > 0 {
+		if err := r.nameReviewers(s); err != nil {
+			return nil, err
+		}
+	}
+
+	var kept Stats
+	for _, stat := range s {
+		switch {
+		case len(self) > 0 && stat.Reviewer == self:
+		case len(selfName) > 0 && strings.EqualFold(stat.Name, selfName):
+		case r.isExcluded(stat):
+		case isBot(bots, stat):
+		default:
+			kept = append(kept, stat)
+		}
+	}
+
+	return kept, nil
+}
+
+// isExcluded reports whether one of ExcludedReviewers appears in the Stat's
+// email or name.
+func (r *ContributionCounter) isExcluded(stat *Stat) bool {
+	email, name := strings.ToLower(stat.Reviewer), strings.ToLower(stat.Name)
+	for _, ex := range r.ExcludedReviewers {
+		ex = strings.ToLower(ex)
+		if len(ex) > 0 && (strings.Contains(email, ex) || strings.Contains(name, ex)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selfIdentity determines the email of the person running the analysis. An
+// explicit SelfIdentity takes precedence over the repository's git config,
+// which in CI usually belongs to a bot rather than the branch author.
+func (r *ContributionCounter) selfIdentity() (string, error) {
+	if len(r.SelfIdentity) > 0 {
+		return r.SelfIdentity, nil
+	}
+
+	email, err := r.gitConfig("user.email")
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read user.email from git config")
+	}
+
+	return email, nil
+}
+
+// attribution records who last changed a counted line, in which commit, and
+// on what date (as "YYYY-MM-DD"). It is worth units of experience, or one
+// when that is zero, which is all but WeightLines ever needs.
+type attribution struct {
+	author string
+	rev    string
+	date   string
+	units  int64
+}
+
+// size is how many units of experience the attribution is worth.
+func (a attribution) size() int64 {
+	if a.units > 0 {
+		return a.units
+	}
 
-			email := string(bi.email)
-			// Normalize scanned email based on what we found in the mailmap
-			if e, ok := r.Mailmap[email]; ok {
-				attributions = append(attributions, e)
-			} else {
-				attributions = append(attributions, email)
+	return 1
+}
+
+// contributions maps each changed path to the attributions for the counted
+// lines in it.
+type contributions map[string][]attribution
+
+// blameReport carries the attributions for every counted line in a blamed
+// file, or why it couldn't be blamed.
+type blameReport struct {
+	path         string
+	attributions []attribution
+	err          error
+}
+
+// concurrency is how many files may be blamed at once. See Concurrency.
+func (r *ContributionCounter) concurrency() int {
+	if r.Concurrency > 0 {
+		return r.Concurrency
+	}
+
+	return runtime.NumCPU()
+}
+
+// generateCounts blames every path at the base concurrently, at most
+// Concurrency at a time. Every blame is waited for even when one fails, so
+// none are left running, and the first failure is returned naming its path.
+// Once the counter's context is done, that is the error.
+func (r *ContributionCounter) generateCounts(paths []string) (contributions, error) {
+	// Get the base commit so we can determine what the experience was *before*
+	// the author got to the file.
+	mc, err := r.baseCommit()
+	if err != nil {
+		r.verbosef("Error blaming changed files: unable to find commit for base\n")
+		return nil, err
+	}
+
+	// Buffered so that no blame waits on the others to be collected, while
+	// slots keeps more than concurrency git processes from running at once
+	reporter := make(chan blameReport, len(paths))
+	slots := make(chan struct{}, r.concurrency())
+	for _, p := range paths {
+		go func(p string) {
+			slots <- struct{}{}
+			attributions, err := r.blame(p, mc.Hash)
+			<-slots
+			reporter <- blameReport{p, attributions, err}
+		}(p)
+	}
+
+	byFile := make(contributions)
+	for range paths {
+		report := <-reporter
+		if report.err != nil {
+			if err == nil {
+				err = errors.Wrapf(report.err, "unable to blame %s", report.path)
 			}
-		} else {
-			return errors.Wrap(err, "issue parsing a line in git blame output")
+			r.verbosef("Error blaming changed files: issue running git blame for %s\n", report.path)
+			continue
+		}
+		byFile[report.path] = report.attributions
+	}
+
+	if ctxErr := r.context().Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return byFile, nil
+}
+
+// blame attributes the lines of a file at a specific commit (usually
+// "master" or whatever the base branch is) through the Backend, and keeps
+// those that count.
+func (r *ContributionCounter) blame(path string, rev plumbing.Hash) ([]attribution, error) {
+	lines, err := r.backend().Blame(r, rev, path)
+	if err != nil {
+		return nil, err
+	}
+
+	since := r.since()
+	var attributions []attribution
+	for _, line := range lines {
+		// since is a string, not a date. However, since the format is just
+		// a "YYYY-MM-DD" string, we can rely on ASCII sorting and just compare
+		// the strings to determine if a line change was committed before or after
+		// our boundary
+		date := line.Date
+		if r.TimeZone != nil && !line.When.IsZero() {
+			date = r.day(line.When)
 		}
+		if since > date || (len(r.Until) > 0 && date > r.Until) {
+			continue
+		}
+
+		// Normalize scanned email based on what we found in the mailmap
+		attributions = append(attributions, attribution{
+			author: reviewerKey(line.Email, r.Mailmap),
+			rev:    line.Rev,
+			date:   date,
+		})
 	}
 
-	reporter <- attributions
-	return scn.Err()
+	return attributions, nil
 }
 
 // blameInfo holds anything we might be interested in reporting out of a git
 // blame shell command result
 type blameInfo struct {
+	rev   []byte
 	email []byte
 	date  []byte
+	when  time.Time
 }
 
 // parseBlameLine takes the bytes for one line of the output of running git
@@ -515,6 +1709,7 @@ func parseBlameLine(line []byte) (blameInfo, error) {
 		bi    blameInfo
 		date  []byte
 		email []byte
+		rev   []byte
 	)
 	rdr := bytes.NewReader(line)
 
@@ -525,6 +1720,7 @@ func parseBlameLine(line []byte) (blameInfo, error) {
 				rdr.UnreadRune()
 				break
 			}
+			rev = append(rev, string(r)...)
 		} else {
 			return bi, errors.Wrap(err, "unable to read over rev")
 		}
@@ -583,7 +1779,15 @@ func parseBlameLine(line []byte) (blameInfo, error) {
 		date = append(date, b)
 	}
 
-	bi = blameInfo{email, date}
+	// The time and zone follow (" HH:MM:SS -0700"), for when the day has to
+	// be found elsewhere
+	stamp := make([]byte, len(" 15:04:05 -0700"))
+	var when time.Time
+	if _, err := io.ReadFull(rdr, stamp); err == nil {
+		when, _ = time.Parse("2006-01-02 15:04:05 -0700", string(date)+string(stamp))
+	}
+
+	bi = blameInfo{rev, email, date, when}
 	return bi, nil
 }
 
@@ -596,27 +1800,67 @@ func reviewerKey(email string, mm mailmap) string {
 	return email
 }
 
-// chooseTopN consumes the greatest 'n' Stat objects from a Stats list.
+// chooseTopN returns the greatest 'n' Stat objects from a Stats list, most
+// experienced first, sorting a copy so s is left as it was. Equally
+// experienced collaborators keep their order in s, so the seeded tie-breaking
+// in candidates decides between them.
 func chooseTopN(n int, s Stats) Stats {
-	var top Stats
-	heap.Init(&top)
+	top := make(Stats, len(s))
+	copy(top, s)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].Percentage > top[j].Percentage
+	})
+
+	if len(top) > n {
+		top = top[:n]
+	}
 
-	for _, stat := range s {
-		stat := stat
+	return top
+}
 
-		if top.Len() < n || stat.Percentage > top[0].Percentage {
-			// Replace the largest item in the heap with this one
-			// This way our heap never grows larger than it needs to be
-			if top.Len() == n {
-				heap.Pop(&top)
-			}
+// ErrNilCounter is returned when methods are called on a nil
+// *ContributionCounter.
+var ErrNilCounter = errors.New("nil ContributionCounter")
 
-			heap.Push(&top, stat)
-		}
-	}
-	sort.Sort(sort.Reverse(top))
+// ErrNoRepository is returned when no Repo was given and the current
+// directory isn't a git repository either.
+var ErrNoRepository = errors.New("no git repository found")
 
-	return top
+// ErrEmptyRepository is returned when the repository has no commits yet, so
+// there is no history to find reviewers in.
+var ErrEmptyRepository = errors.New("repository has no commits")
+
+// ErrDiffTooLarge is returned instead of analyzing a diff that touches more
+// files than MaxDiffFiles allows.
+type ErrDiffTooLarge struct {
+	Files int
+	Limit int
+}
+
+func (e ErrDiffTooLarge) Error() string {
+	return fmt.Sprintf("diff changes %d files, more than the limit of %d", e.Files, e.Limit)
+}
+
+// ErrInsufficientReviewers is returned with the reviewers that were found
+// when StrictMaxReviewers is on and fewer qualified than were wanted.
+type ErrInsufficientReviewers struct {
+	Found  int
+	Wanted int
+}
+
+func (e ErrInsufficientReviewers) Error() string {
+	return fmt.Sprintf("found %d qualified reviewers, fewer than the %d wanted", e.Found, e.Wanted)
+}
+
+// ErrCriticalPathUncovered is returned when nobody left after exclusions and
+// eligibility filtering has experience with a changed file under one of the
+// CriticalPaths.
+type ErrCriticalPathUncovered struct {
+	Path string
+}
+
+func (e ErrCriticalPathUncovered) Error() string {
+	return fmt.Sprintf("no eligible reviewer has experience with critical path %s", e.Path)
 }
 
 type NoReviewersErr interface {
@@ -633,3 +1877,16 @@ func (nre noReviewersErr) Error() string {
 func (nre noReviewersErr) Help() string {
 	return "Try using a wider date range"
 }
+
+// allExcludedErr is returned by score when ExcludeSelf, ExcludedReviewers,
+// leaving out bots, or MinCommits leave no one. It never reaches callers:
+// FindReviewerStats, FindReport and QuorumReviewers suggest no one instead.
+type allExcludedErr struct{}
+
+func (e allExcludedErr) Error() string {
+	return "every reviewer found was excluded"
+}
+
+func (e allExcludedErr) Help() string {
+	return "Try excluding fewer reviewers"
+}
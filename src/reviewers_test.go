@@ -6,7 +6,18 @@ Sniperkit-Bot
 package gitreviewers
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultIgnoreExtensions(t *testing.T) {
@@ -38,6 +49,54 @@ func TestDefaultIgnoreExtensions(t *testing.T) {
 	}
 }
 
+func TestConsiderExtIgnoresCase(t *testing.T) {
+	cases := []struct {
+		Path     string
+		Opts     *ContributionCounter
+		Expected bool
+	}{
+		{"IMAGE.SVG", &ContributionCounter{}, false},
+		{"Logo.Svg", &ContributionCounter{}, false},
+		{"lib/App.COFFEE", &ContributionCounter{IgnoredExtensions: []string{"coffee"}}, false},
+		{"lib/app.coffee", &ContributionCounter{IgnoredExtensions: []string{"COFFEE"}}, false},
+		{"lib/app.js", &ContributionCounter{IgnoredExtensions: []string{"COFFEE"}}, true},
+		{"cmd/MAIN.GO", &ContributionCounter{OnlyExtensions: []string{"go"}}, true},
+		{"cmd/main.go", &ContributionCounter{OnlyExtensions: []string{"Go"}}, true},
+		{"cmd/main.rb", &ContributionCounter{OnlyExtensions: []string{"GO"}}, false},
+	}
+
+	for _, c := range cases {
+		if actual := considerExt(c.Path, c.Opts); actual != c.Expected {
+			t.Errorf("Got %t considering %s with ignored %v and only %v, expected %t\n",
+				actual, c.Path, c.Opts.IgnoredExtensions, c.Opts.OnlyExtensions, c.Expected)
+		}
+	}
+}
+
+func TestConsiderExtOnDotBoundary(t *testing.T) {
+	cases := []struct {
+		Path     string
+		Opts     *ContributionCounter
+		Expected bool
+	}{
+		{"main.go", &ContributionCounter{OnlyExtensions: []string{"go"}}, true},
+		{"cargo", &ContributionCounter{OnlyExtensions: []string{"go"}}, false},
+		{"main.go", &ContributionCounter{OnlyExtensions: []string{".go"}}, true},
+		{"foo.js", &ContributionCounter{IgnoredExtensions: []string{"s"}}, true},
+		{"bar.s", &ContributionCounter{IgnoredExtensions: []string{"s"}}, false},
+		{"bar.s", &ContributionCounter{IgnoredExtensions: []string{".s"}}, false},
+		{"dist.tar.gz", &ContributionCounter{IgnoredExtensions: []string{"tar.gz"}}, false},
+		{"main.go", &ContributionCounter{OnlyExtensions: []string{""}}, false},
+	}
+
+	for _, c := range cases {
+		if actual := considerExt(c.Path, c.Opts); actual != c.Expected {
+			t.Errorf("Got %t considering %s with ignored %q and only %q, expected %t\n",
+				actual, c.Path, c.Opts.IgnoredExtensions, c.Opts.OnlyExtensions, c.Expected)
+		}
+	}
+}
+
 func TestChooseTopN(t *testing.T) {
 	var (
 		stats      Stats
@@ -46,7 +105,7 @@ func TestChooseTopN(t *testing.T) {
 	)
 
 	for i := 0; i < srcSize; i++ {
-		stats = append(stats, &Stat{"", float64(i)})
+		stats = append(stats, &Stat{Percentage: float64(i)})
 	}
 
 	actual := chooseTopN(outputSize, stats)
@@ -64,3 +123,1178 @@ func TestChooseTopN(t *testing.T) {
 	}
 
 }
+
+func TestExcludeSelfFromGitConfig(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	if out := f.reviewers(r); !strings.Contains(out, "me@git-reviewer.com") {
+		t.Errorf("Expected self to be suggested by default, got:\n%s", out)
+	}
+
+	r.ExcludeSelf = true
+	out := f.reviewers(r)
+	if strings.Contains(out, "me@git-reviewer.com") {
+		t.Errorf("Expected git config identity to be excluded, got:\n%s", out)
+	}
+	if !strings.Contains(out, "abe@git-reviewer.com") {
+		t.Errorf("Expected other reviewers to remain, got:\n%s", out)
+	}
+}
+
+func TestExcludeSelfIdentityOverridesGitConfig(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	r.ExcludeSelf = true
+	r.SelfIdentity = "abe@git-reviewer.com"
+
+	out := f.reviewers(r)
+	if strings.Contains(out, "abe@git-reviewer.com") {
+		t.Errorf("Expected SelfIdentity to be excluded, got:\n%s", out)
+	}
+	if !strings.Contains(out, "me@git-reviewer.com") {
+		t.Errorf("Expected git config identity to be kept, got:\n%s", out)
+	}
+}
+
+func TestExcludeSelfByName(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// Commits from an old address still belong to whoever is configured now
+	f.git("config", "user.email", "me@new.git-reviewer.com")
+	f.git("config", "user.name", "Pat Smith")
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "a\nb\n"})
+	f.commitAs("Pat Smith", "pat@old.git-reviewer.com", map[string]string{"b.go": "c\nd\n"})
+
+	r := f.counter()
+	r.ExcludeSelf = true
+
+	stats, err := r.FindReviewerStats([]string{"a.go", "b.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if len(stats) != 1 || stats[0].Reviewer != "abe@git-reviewer.com" {
+		t.Errorf("Got %v, expected only abe once Pat Smith is excluded by name\n", stats)
+	}
+}
+
+func TestExcludedReviewers(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	cases := []struct {
+		Excluded []string
+		Expected []string
+	}{
+		{nil, []string{"abe@git-reviewer.com", "george@git-reviewer.com", "me@git-reviewer.com"}},
+		{[]string{"GEORGE"}, []string{"abe@git-reviewer.com", "me@git-reviewer.com"}},
+		{[]string{"abe@", "me"}, []string{"george@git-reviewer.com"}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.ExcludedReviewers = c.Excluded
+
+		stats, err := r.FindReviewerStats([]string{"main.go", "util.go", "doc.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		var actual []string
+		for _, stat := range stats {
+			actual = append(actual, stat.Reviewer)
+		}
+		sort.Strings(actual)
+		if !reflect.DeepEqual(actual, c.Expected) {
+			t.Errorf("Got %v excluding %v, expected %v\n", actual, c.Excluded, c.Expected)
+		}
+	}
+}
+
+func TestExcludeSelfOnlyReviewer(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": "a\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": "b\n"})
+
+	r := f.counter()
+	r.ExcludeSelf = true
+
+	stats, err := r.FindReviewerStats([]string{"a.go"})
+	if err != nil || len(stats) != 0 {
+		t.Errorf("Got %v and error %v, expected no reviewers and no error\n", stats, err)
+	}
+
+	out, err := r.FindReviewers([]string{"a.go"})
+	if err != nil || len(out) != 0 {
+		t.Errorf("Got '%s' and error %v, expected no output and no error\n", out, err)
+	}
+}
+
+func TestFindReviewersForCommits(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"a.go": "a\n", "b.go": "b\n", "c.go": "c\n", "d.go": "d\n",
+	})
+	first := f.commit("george@git-reviewer.com", map[string]string{
+		"a.go": "a\na\n", "b.go": "b\nb\n",
+	})
+	second := f.commit("george@git-reviewer.com", map[string]string{
+		"b.go": "b\nb\nb\n", "c.go": "c\nc\n",
+	})
+
+	r := f.counter()
+	files, err := r.FindCommitFiles([]string{first, second})
+	if err != nil {
+		t.Fatalf("Unexpected error finding commit files: %v\n", err)
+	}
+
+	sort.Strings(files)
+	if expected := []string{"a.go", "b.go", "c.go"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Got files %v, expected %v\n", files, expected)
+	}
+
+	out, err := r.FindReviewersForCommits([]string{first, second})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if !strings.Contains(out, "george@git-reviewer.com") ||
+		!strings.Contains(out, "abe@git-reviewer.com") {
+		t.Errorf("Expected both authors to be suggested, got:\n%s", out)
+	}
+}
+
+func TestMinCommits(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// Counts add up across files: dave's nine commits are split between two.
+	counts := map[string]int{"abe": 1, "bob": 1, "carol": 5, "dave": 9}
+	for _, name := range []string{"abe", "bob", "carol", "dave"} {
+		for i := 0; i < counts[name]; i++ {
+			file := "a.go"
+			if name == "dave" && i%2 == 1 {
+				file = "b.go"
+			}
+			f.commit(name+"@git-reviewer.com", map[string]string{
+				file: fmt.Sprintf("%s %d\n", name, i),
+			})
+		}
+	}
+
+	cases := []struct {
+		Min      int
+		Expected []string
+	}{
+		{0, []string{"abe@git-reviewer.com", "bob@git-reviewer.com", "carol@git-reviewer.com", "dave@git-reviewer.com"}},
+		{2, []string{"carol@git-reviewer.com", "dave@git-reviewer.com"}},
+		{6, []string{"dave@git-reviewer.com"}},
+		{10, nil},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Weight = WeightCommits
+		r.MaxReviewers = 4
+		r.MinCommits = c.Min
+
+		stats, err := r.FindReviewerStats([]string{"a.go", "b.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers with a minimum of %d: %v\n", c.Min, err)
+		}
+
+		got := make(map[string]bool)
+		for _, stat := range stats {
+			got[stat.Reviewer] = true
+		}
+		ok := len(got) == len(c.Expected)
+		for _, reviewer := range c.Expected {
+			ok = ok && got[reviewer]
+		}
+		if !ok {
+			t.Errorf("Got reviewers %v with a minimum of %d, expected %v\n", got, c.Min, c.Expected)
+		}
+	}
+}
+
+func TestMinDistinctReviewers(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// abe owns nearly everything; the rest have a line each.
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"a.go": "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+	})
+	for _, email := range []string{"bob", "carol", "dave", "erin"} {
+		f.commit(email+"@git-reviewer.com", map[string]string{
+			email + ".go": email + "\n",
+		})
+	}
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"a.go": "x\n", "bob.go": "x\n", "carol.go": "x\n", "dave.go": "x\n", "erin.go": "x\n",
+	})
+
+	cases := []struct {
+		Min, Expected int
+	}{
+		{0, 3},
+		{2, 3},
+		{4, 4},
+		{10, 5},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.MinDistinctReviewers = c.Min
+
+		// Two header lines precede the reviewers
+		lines := strings.Split(strings.TrimSpace(f.reviewers(r)), "\n")
+		if n := len(lines) - 2; n != c.Expected {
+			t.Errorf("Got %d reviewers with a minimum of %d, expected %d\n",
+				n, c.Min, c.Expected)
+		}
+	}
+}
+
+func TestZeroValueCounter(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unable to get working directory: %v\n", err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(f.dir); err != nil {
+		t.Fatalf("Unable to enter fixture: %v\n", err)
+	}
+
+	var r ContributionCounter
+	r.BuildMailmap()
+
+	if _, err := r.BranchBehind(); err != nil {
+		t.Errorf("BranchBehind on zero value: %v\n", err)
+	}
+
+	files, err := r.FindFiles()
+	if err != nil || len(files) == 0 {
+		t.Errorf("FindFiles on zero value found %v: %v\n", files, err)
+	}
+
+	if _, err := r.FindReviewers(files); err != nil {
+		t.Errorf("FindReviewers on zero value: %v\n", err)
+	}
+
+	if _, err := r.FindCommitFiles([]string{"HEAD"}); err != nil {
+		t.Errorf("FindCommitFiles on zero value: %v\n", err)
+	}
+
+	if _, err := r.FindReviewersForCommits([]string{"HEAD"}); err != nil {
+		t.Errorf("FindReviewersForCommits on zero value: %v\n", err)
+	}
+
+	if _, err := r.QuorumReviewers(2); err != nil {
+		t.Errorf("QuorumReviewers on zero value: %v\n", err)
+	}
+}
+
+func TestNilCounter(t *testing.T) {
+	var r *ContributionCounter
+	r.BuildMailmap()
+
+	if _, err := r.BranchBehind(); err != ErrNilCounter {
+		t.Errorf("BranchBehind returned '%v', expected ErrNilCounter\n", err)
+	}
+	if _, err := r.FindFiles(); err != ErrNilCounter {
+		t.Errorf("FindFiles returned '%v', expected ErrNilCounter\n", err)
+	}
+	if _, err := r.FindReviewers(nil); err != ErrNilCounter {
+		t.Errorf("FindReviewers returned '%v', expected ErrNilCounter\n", err)
+	}
+	if _, err := r.FindCommitFiles(nil); err != ErrNilCounter {
+		t.Errorf("FindCommitFiles returned '%v', expected ErrNilCounter\n", err)
+	}
+	if _, err := r.FindReviewersForCommits(nil); err != ErrNilCounter {
+		t.Errorf("FindReviewersForCommits returned '%v', expected ErrNilCounter\n", err)
+	}
+	if _, err := r.QuorumReviewers(2); err != ErrNilCounter {
+		t.Errorf("QuorumReviewers returned '%v', expected ErrNilCounter\n", err)
+	}
+}
+
+func TestNoRepository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-reviewer")
+	if err != nil {
+		t.Fatalf("Unable to create directory: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unable to get working directory: %v\n", err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Unable to enter directory: %v\n", err)
+	}
+
+	var r ContributionCounter
+	if _, err := r.FindFiles(); err != ErrNoRepository {
+		t.Errorf("FindFiles returned '%v', expected ErrNoRepository\n", err)
+	}
+}
+
+func TestFindReviewersForLastCommits(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "a\n", "b.go": "b\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "a\na\n"})
+	f.commit("carol@git-reviewer.com", map[string]string{"b.go": "b\nb\n"})
+
+	r := f.counter()
+
+	shas, err := r.lastCommits(1)
+	if err != nil {
+		t.Fatalf("Unexpected error listing commits: %v\n", err)
+	}
+	files, err := r.FindCommitFiles(shas)
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	if !reflect.DeepEqual(files, []string{"b.go"}) {
+		t.Errorf("Got files %v for the last commit, expected [b.go]\n", files)
+	}
+
+	out, err := r.FindReviewersForLastCommits(1)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if strings.Contains(out, "george@git-reviewer.com") {
+		t.Errorf("Expected only b.go reviewers for the last commit, got:\n%s", out)
+	}
+
+	// Asking for more commits than exist covers the whole history
+	if shas, err = r.lastCommits(100); err != nil || len(shas) != 3 {
+		t.Errorf("Got %d commits for n beyond history, expected 3: %v\n", len(shas), err)
+	}
+	out, err = r.FindReviewersForLastCommits(100)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	for _, email := range []string{"abe", "george", "carol"} {
+		if !strings.Contains(out, email+"@git-reviewer.com") {
+			t.Errorf("Expected %s to be suggested across all history, got:\n%s", email, out)
+		}
+	}
+
+	if _, err := r.FindReviewersForLastCommits(0); err == nil {
+		t.Error("Expected an error asking for zero commits")
+	}
+
+	// A merge is one commit, not the newer ones on the branch it brought in
+	f.git("branch", "side")
+	before := f.commit("carol@git-reviewer.com", map[string]string{"b.go": "b\nb\nc\n"})
+	f.git("checkout", "-q", "side")
+	f.commit("dave@git-reviewer.com", map[string]string{"a.go": "a\na\nd\n"})
+	f.commit("dave@git-reviewer.com", map[string]string{"a.go": "a\na\nd\nd\n"})
+	f.git("checkout", "-q", "master")
+	f.git("merge", "-q", "--no-ff", "-m", "Merge side", "side")
+	merge := f.git("rev-parse", "HEAD")
+
+	if shas, err = r.lastCommits(2); err != nil {
+		t.Fatalf("Unexpected error listing commits: %v\n", err)
+	}
+	if expected := []string{merge, before}; !reflect.DeepEqual(shas, expected) {
+		t.Errorf("Got commits %v across a merge, expected %v\n", shas, expected)
+	}
+}
+
+func TestFindReviewersFromReaderWithWorkDir(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "a\n", "b.go": "b\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"c.go": "c\n"})
+
+	// Run from somewhere other than the repository to be sure WorkDir is used
+	dir, err := ioutil.TempDir("", "git-reviewer")
+	if err != nil {
+		t.Fatalf("Unable to create directory: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unable to get working directory: %v\n", err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Unable to enter directory: %v\n", err)
+	}
+
+	r := &ContributionCounter{WorkDir: f.dir}
+	out, err := r.FindReviewersFromReader(strings.NewReader("a.go\n\nb.go\na.go\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	if !strings.Contains(out, "abe@git-reviewer.com") {
+		t.Errorf("Expected abe to be suggested, got:\n%s", out)
+	}
+	if strings.Contains(out, "george@git-reviewer.com") {
+		t.Errorf("Expected files outside the list to be ignored, got:\n%s", out)
+	}
+}
+
+func TestRandSeedIsReproducible(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// Everyone owns exactly one line, so only tie-breaking decides the top 3
+	files := make(map[string]string)
+	names := []string{"abe", "bob", "carol", "dave", "erin", "frank"}
+	for _, name := range names {
+		f.commit(name+"@git-reviewer.com", map[string]string{name + ".go": name + "\n"})
+		files[name+".go"] = "x\n"
+	}
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", files)
+
+	for _, seed := range []int64{0, 42} {
+		var first string
+		for i := 0; i < 5; i++ {
+			r := f.counter()
+			r.RandSeed = seed
+
+			out := f.reviewers(r)
+			if i == 0 {
+				first = out
+			} else if out != first {
+				t.Errorf("Got different output with seed %d:\n%s\nthen:\n%s", seed, first, out)
+			}
+		}
+	}
+}
+
+func TestMaxDiffFiles(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unable to find files: %v\n", err)
+	}
+
+	r.MaxDiffFiles = len(files)
+	if _, err := r.FindReviewers(files); err != nil {
+		t.Errorf("Unexpected error at the file limit: %v\n", err)
+	}
+
+	r.MaxDiffFiles = len(files) - 1
+	_, err = r.FindReviewers(files)
+	if e, ok := err.(ErrDiffTooLarge); !ok {
+		t.Errorf("Got '%v' above the file limit, expected ErrDiffTooLarge\n", err)
+	} else if e.Files != len(files) || e.Limit != r.MaxDiffFiles {
+		t.Errorf("Got %+v, expected %d files over a limit of %d\n",
+			e, len(files), r.MaxDiffFiles)
+	}
+}
+
+func TestScorePrecision(t *testing.T) {
+	cases := []struct {
+		Precision int
+		Expected  string
+	}{
+		// Zero selects the default, so no decimal places takes WholeNumbers
+		{WholeNumbers, "12%"},
+		{0, "12.35%"},
+		{2, "12.35%"},
+		{4, "12.3456%"},
+	}
+
+	for _, c := range cases {
+		r := &ContributionCounter{ScorePrecision: c.Precision}
+		if actual := formatScore(0.123456, r.scorePrecision()); actual != c.Expected {
+			t.Errorf("Got '%s' at precision %d, expected '%s'\n",
+				actual, c.Precision, c.Expected)
+		}
+	}
+
+	if actual := (&Stat{Reviewer: "abe", Percentage: 0.5}).String(); actual != "  50.00%\tabe" {
+		t.Errorf("Got Stat string '%s', expected default precision\n", actual)
+	}
+}
+
+func TestScorePrecisionInReviewers(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	r := f.counter()
+	r.ScorePrecision = 4
+
+	// abe owns 3 of the 6 counted lines
+	if out := f.reviewers(r); !strings.Contains(out, "50.0000%") {
+		t.Errorf("Expected scores with four decimal places, got:\n%s", out)
+	}
+
+	r.ScorePrecision = WholeNumbers
+	if out := f.reviewers(r); !strings.Contains(out, "50%") {
+		t.Errorf("Expected whole number scores, got:\n%s", out)
+	}
+
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unable to find files: %v\n", err)
+	}
+	stats, err := r.FindReviewerStats(files)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewer stats: %v\n", err)
+	}
+	if len(stats) == 0 || !strings.HasPrefix(stats[0].String(), "  50%\t") {
+		t.Errorf("Expected Stat strings with whole number scores, got %v\n", stats)
+	}
+
+	report, err := r.FindReport(files)
+	if err != nil {
+		t.Fatalf("Unexpected error finding report: %v\n", err)
+	}
+	var md bytes.Buffer
+	if err := (MarkdownFormatter{}).Write(&md, report); err != nil {
+		t.Fatalf("Unexpected error writing Markdown: %v\n", err)
+	}
+	if !strings.Contains(md.String(), " | 50% | ") {
+		t.Errorf("Expected a Markdown table with whole number scores, got:\n%s", md.String())
+	}
+}
+
+func TestPostProcess(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"b.go": "1\n2\n"})
+	f.commit("carol@git-reviewer.com", map[string]string{"c.go": "1\n"})
+	f.commit("dave@git-reviewer.com", map[string]string{"d.go": "1\n"})
+	paths := []string{"a.go", "b.go", "c.go", "d.go"}
+
+	var seen Stats
+	r := f.counter()
+	r.PostProcess = func(s Stats) Stats {
+		seen = s
+
+		// Reverse the ranking and put a synthetic reviewer up front
+		reversed := Stats{&Stat{Reviewer: "team-lead@git-reviewer.com"}}
+		for i := len(s) - 1; i >= 0; i-- {
+			reversed = append(reversed, s[i])
+		}
+		return reversed
+	}
+
+	stats, err := r.FindReviewerStats(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	if l := len(seen); l != 4 {
+		t.Errorf("PostProcess saw %d candidates, expected all 4\n", l)
+	} else if seen[0].Reviewer != "abe@git-reviewer.com" || seen[1].Reviewer != "bob@git-reviewer.com" {
+		t.Errorf("Expected PostProcess to see candidates in rank order, got %v\n", seen)
+	}
+
+	var got []string
+	for _, stat := range stats {
+		got = append(got, stat.Reviewer)
+	}
+	expected := []string{"team-lead@git-reviewer.com", seen[3].Reviewer, seen[2].Reviewer}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got reviewers %v, expected %v\n", got, expected)
+	}
+}
+
+func TestStatFiles(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n", "b.go": "1\n", "c.go": "1\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n"})
+
+	stats, err := f.counter().FindReviewerStats([]string{"a.go", "b.go", "c.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	expected := map[string]int{"abe@git-reviewer.com": 3, "bob@git-reviewer.com": 1}
+	for _, stat := range stats {
+		if stat.Files != expected[stat.Reviewer] {
+			t.Errorf("Got %d files for %s, expected %d\n",
+				stat.Files, stat.Reviewer, expected[stat.Reviewer])
+		}
+	}
+
+	s := Stat{Reviewer: "abe", Percentage: 0.5, Files: 7}
+	if actual := s.String(); actual != "  50.00%\tabe (7 files)" {
+		t.Errorf("Got Stat string '%s', expected the file count\n", actual)
+	}
+}
+
+func TestChooseTopNKeepsTieOrder(t *testing.T) {
+	var stats Stats
+	for _, r := range []string{"a", "b", "c", "d", "e"} {
+		stats = append(stats, &Stat{Reviewer: r, Percentage: 0.1})
+	}
+	stats = append(stats, &Stat{Reviewer: "f", Percentage: 0.5})
+
+	var order []string
+	for _, stat := range chooseTopN(4, stats) {
+		order = append(order, stat.Reviewer)
+	}
+
+	if expected := []string{"f", "a", "b", "c"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Got reviewers %v, expected %v\n", order, expected)
+	}
+}
+
+func TestRankedPairs(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n"})
+
+	stats, err := f.counter().FindReviewerStats([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	expected := []RankedPair{
+		{Reviewer: "bob@git-reviewer.com", Count: 3},
+		{Reviewer: "george@git-reviewer.com", Count: 2},
+		{Reviewer: "abe@git-reviewer.com", Count: 1},
+	}
+	if actual := stats.RankedPairs(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Got pairs %v, expected %v\n", actual, expected)
+	}
+}
+
+func TestNoScorePaths(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"main.go": "1\n"})
+	f.commit("bot@git-reviewer.com", map[string]string{
+		"go.sum":        "1\n2\n3\n4\n5\n6\n7\n8\n",
+		"web/yarn.lock": "1\n2\n3\n4\n5\n6\n7\n8\n",
+	})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{
+		"main.go":       "1\n2\n",
+		"go.sum":        "2\n",
+		"web/yarn.lock": "2\n",
+	})
+
+	r := f.counter()
+	r.NoScorePaths = []string{"go.sum", "*.lock"}
+
+	files, err := r.FindFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error finding files: %v\n", err)
+	}
+	sort.Strings(files)
+	if expected := []string{"go.sum", "main.go", "web/yarn.lock"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Got files %v, expected %v\n", files, expected)
+	}
+
+	stats, err := r.FindReviewerStats(files)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if len(stats) != 1 || stats[0].Reviewer != "abe@git-reviewer.com" || stats[0].Percentage != 1 {
+		t.Errorf("Got reviewers %v, expected only abe\n", stats)
+	}
+}
+
+func TestRank(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n"})
+
+	r := f.counter()
+	stats, err := r.FindReviewerStats([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	// abe and bob tie for first, so george is third
+	expected := map[string]int{
+		"abe@git-reviewer.com":    1,
+		"bob@git-reviewer.com":    1,
+		"george@git-reviewer.com": 3,
+	}
+	for _, stat := range stats {
+		if stat.Rank != expected[stat.Reviewer] {
+			t.Errorf("Got rank %d for %s, expected %d\n", stat.Rank, stat.Reviewer, expected[stat.Reviewer])
+		}
+	}
+
+	r.ShowRank = true
+	out, err := r.FindReviewers([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if !strings.Contains(out, "#3\tgeorge@git-reviewer.com") {
+		t.Errorf("Got output:\n%s\nexpected george numbered third\n", out)
+	}
+}
+
+func TestMaxReviewers(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"a.go": "1\n2\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commit("jane@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+
+	cases := []struct {
+		Max      int
+		Strict   bool
+		Found    int
+		Expected error
+	}{
+		{0, false, 3, nil},
+		{1, false, 1, nil},
+		{2, true, 2, nil},
+		{3, false, 3, nil},
+		{4, true, 4, nil},
+		{6, false, 4, nil},
+		{100, false, 4, nil},
+		// Only four people have worked on the file
+		{6, true, 4, ErrInsufficientReviewers{Found: 4, Wanted: 6}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.MaxReviewers = c.Max
+		r.StrictMaxReviewers = c.Strict
+
+		stats, err := r.FindReviewerStats([]string{"a.go"})
+		if err != c.Expected {
+			t.Errorf("Got error '%v' for %d strict %v, expected '%v'\n", err, c.Max, c.Strict, c.Expected)
+		}
+		if len(stats) != c.Found {
+			t.Errorf("Got %d reviewers for %d strict %v, expected %d\n", len(stats), c.Max, c.Strict, c.Found)
+		}
+	}
+}
+
+func TestDefaultSince(t *testing.T) {
+	sixMonths := time.Now().AddDate(0, -6, 0).Format("2006-01-02")
+	twoYears := time.Now().AddDate(-2, 0, 0)
+
+	// A busy repository has plenty of history in the last 6 months
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	if since, err := f.counter().defaultSince("master"); err != nil || since != sixMonths {
+		t.Errorf("Got default since '%s' and error %v, expected %s\n", since, err, sixMonths)
+	}
+
+	// A quiet one goes back as far as it takes to find some
+	q := newFixture(t)
+	defer q.cleanup()
+
+	q.commitAt("abe@git-reviewer.com", twoYears, map[string]string{"a.go": "1\n"})
+	q.git("checkout", "-q", "-b", "feature")
+	q.commit("me@git-reviewer.com", map[string]string{"a.go": "1\n2\n"})
+
+	r := q.counter()
+	since, err := r.defaultSince("master")
+	if err != nil {
+		t.Fatalf("Unexpected error finding default since: %v\n", err)
+	}
+	if expected := twoYears.Format("2006-01-02"); since != expected {
+		t.Errorf("Got default since '%s', expected %s\n", since, expected)
+	}
+
+	stats, err := r.FindReviewerStats([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if len(stats) != 1 || stats[0].Reviewer != "abe@git-reviewer.com" {
+		t.Errorf("Got reviewers %v, expected abe\n", stats)
+	}
+
+	// The default is worked out afresh every run rather than pinned
+	if len(r.Since) > 0 {
+		t.Errorf("Expected Since to stay unset, got '%s'\n", r.Since)
+	}
+}
+
+func TestDefaultsResolvedOncePerRun(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	log := filepath.Join(f.dir, ".git", "commands.log")
+	r := f.counter()
+	wrapGit(r, log, `printf '%s\n' "$*" >> "$0"; exec "$@"`)
+
+	if _, err := r.FindReviewers([]string{"main.go"}); err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	out, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatalf("Wrapper never ran: %v\n", err)
+	}
+	for _, cmd := range []string{"symbolic-ref", "--first-parent"} {
+		if n := strings.Count(string(out), cmd); n != 1 {
+			t.Errorf("Ran %s %d times in one run, expected once:\n%s", cmd, n, out)
+		}
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	stats, err := f.counter().FindReviewerStats([]string{"main.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("Unable to encode reviewers: %v\n", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unable to decode reviewers: %v\n%s", err, data)
+	}
+	if len(decoded) != 1 {
+		t.Fatalf("Got reviewers %s, expected abe\n", data)
+	}
+
+	expected := map[string]interface{}{
+		"reviewer":   "abe@git-reviewer.com",
+		"name":       "abe",
+		"percentage": 1.0,
+		"count":      3.0,
+		"lastCommit": stats[0].LastCommit,
+		"files":      1.0,
+		"rank":       1.0,
+		"reasons":    []interface{}{ReasonLineOwnership, ReasonRecentActivity},
+	}
+	if !reflect.DeepEqual(decoded[0], expected) {
+		t.Errorf("Got %v, expected %v\n", decoded[0], expected)
+	}
+}
+
+func TestFindReviewersForMerge(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"a.go": "a\n", "b.go": "b\n", "c.go": "c\n", "d.go": "d\n",
+	})
+	for _, topic := range []struct{ Branch, Email, File string }{
+		{"george", "george@git-reviewer.com", "b.go"},
+		{"bob", "bob@git-reviewer.com", "c.go"},
+		{"jane", "jane@git-reviewer.com", "d.go"},
+	} {
+		f.git("checkout", "-q", "-b", topic.Branch, "master")
+		f.commit(topic.Email, map[string]string{topic.File: "x\ny\nz\n"})
+	}
+	f.git("checkout", "-q", "master")
+	plain := f.commit("abe@git-reviewer.com", map[string]string{"a.go": "a\na\n"})
+
+	f.git("merge", "-q", "--no-ff", "-m", "Merge george", "george")
+	merge := strings.TrimSpace(f.git("rev-parse", "HEAD"))
+	f.git("merge", "-q", "--no-ff", "-m", "Merge bob and jane", "bob", "jane")
+	octopus := strings.TrimSpace(f.git("rev-parse", "HEAD"))
+
+	cases := []struct {
+		SHA      string
+		Files    []string
+		Expected []string
+		Absent   []string
+	}{
+		{merge, []string{"b.go"}, []string{"george"}, []string{"bob", "jane"}},
+		{octopus, []string{"c.go", "d.go"}, []string{"bob", "jane"}, []string{"george"}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		files, err := r.FindCommitFiles([]string{c.SHA})
+		if err != nil {
+			t.Fatalf("Unexpected error finding merged files: %v\n", err)
+		}
+		sort.Strings(files)
+		if !reflect.DeepEqual(files, c.Files) {
+			t.Errorf("Got merged files %v, expected %v\n", files, c.Files)
+		}
+
+		out, err := r.FindReviewersForMerge(c.SHA)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+		for _, name := range c.Expected {
+			if !strings.Contains(out, name+"@git-reviewer.com") {
+				t.Errorf("Expected %s to be suggested for %v, got:\n%s", name, c.Files, out)
+			}
+		}
+		for _, name := range c.Absent {
+			if strings.Contains(out, name+"@git-reviewer.com") {
+				t.Errorf("Expected %s not to be suggested for %v, got:\n%s", name, c.Files, out)
+			}
+		}
+	}
+
+	if _, err := f.counter().FindReviewersForMerge(plain); err == nil {
+		t.Error("Expected an error finding reviewers for a commit that isn't a merge")
+	}
+}
+
+func TestReviewerNames(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// Jane changed how she signs her commits, but not her email
+	f.commitAs("Jane Doe", "jane@git-reviewer.com", map[string]string{"a.go": "1\n2\n"})
+	f.commitAs("Jane Q. Doe", "jane@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commitAs("Abe", "abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+
+	stats, err := f.counter().FindReviewerStats([]string{"a.go"})
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("Got reviewers %v, expected jane and abe\n", stats)
+	}
+	jane := stats[0]
+	if jane.Reviewer != "jane@git-reviewer.com" || jane.Count != 3 || jane.Name != "Jane Q. Doe" {
+		t.Errorf("Got %+v, expected 3 lines for jane under her latest name\n", *jane)
+	}
+	if expected := "  75.00%\tJane Q. Doe <jane@git-reviewer.com> (1 file)"; jane.String() != expected {
+		t.Errorf("Got '%s', expected '%s'\n", jane.String(), expected)
+	}
+}
+
+func TestConsiderPathGlobs(t *testing.T) {
+	patterns := []string{"internal/**", "*_test.go", "docs", "cmd/*/", "**/testdata/"}
+	cases := []struct {
+		Path    string
+		Matches bool
+	}{
+		{"internal/a.go", true},
+		{"internal/deep/b.go", true},
+		{"pkg/internal/a.go", false},
+		{"a_test.go", true},
+		{"pkg/sub/a_test.go", true},
+		{"pkg/a.go", false},
+		{"docs/intro.md", true},
+		{"docs.go", true},
+		{"cmd/tool/main.go", true},
+		{"cmd/main.go", false},
+		{"pkg/testdata/golden.txt", true},
+		{"testdata/golden.txt", true},
+		{"testdata.go", false},
+	}
+
+	only := &ContributionCounter{OnlyPaths: patterns}
+	ignored := &ContributionCounter{IgnoredPaths: patterns}
+	for _, c := range cases {
+		if actual := considerPath(c.Path, only); actual != c.Matches {
+			t.Errorf("Got %v considering %s with OnlyPaths, expected %v\n", actual, c.Path, c.Matches)
+		}
+		if actual := considerPath(c.Path, ignored); actual == c.Matches {
+			t.Errorf("Got %v considering %s with IgnoredPaths, expected %v\n", actual, c.Path, !c.Matches)
+		}
+	}
+}
+
+func TestConcurrentBlameTotals(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	authors := []string{"abe", "george", "tom"}
+	var paths []string
+	for i := 0; i < 12; i++ {
+		p := fmt.Sprintf("pkg%d/file.go", i)
+		paths = append(paths, p)
+
+		// Author j writes j+1 lines of every file
+		content := ""
+		for j, author := range authors {
+			content += strings.Repeat(author+"\n", j+1)
+			f.commit(author+"@git-reviewer.com", map[string]string{p: content})
+		}
+	}
+
+	expected := map[string]int64{
+		"abe@git-reviewer.com":    12,
+		"george@git-reviewer.com": 24,
+		"tom@git-reviewer.com":    36,
+	}
+
+	// However the blames interleave, they add up the same way
+	for run := 0; run < 5; run++ {
+		stats, err := f.counter().FindReviewerStats(paths)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(expected) {
+			t.Fatalf("Got %d reviewers, expected %d\n", len(stats), len(expected))
+		}
+		for _, stat := range stats {
+			if stat.Count != expected[stat.Reviewer] || stat.Files != len(paths) {
+				t.Errorf("Got %d lines in %d files for %s, expected %d in %d\n",
+					stat.Count, stat.Files, stat.Reviewer, expected[stat.Reviewer], len(paths))
+			}
+		}
+	}
+
+	// A single file failing to blame fails the whole count, naming the file
+	r := f.counter()
+	wrapGit(r, "-", `[ "$sub" = blame ] && [ "$last" = pkg7/file.go ] && exit 1; exec "$@"`)
+	if _, err := r.FindReviewerStats(paths); err == nil || !strings.Contains(err.Error(), "pkg7/file.go") {
+		t.Errorf("Got error %v, expected one naming pkg7/file.go\n", err)
+	}
+}
+
+func TestMaxWidth(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	long := "a-very-long-reviewer-name@git-reviewer.com"
+	f.commit(long, map[string]string{"a.go": "1\n2\n3\n"})
+	f.commit("tom@git-reviewer.com", map[string]string{"b.go": "1\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": "x\n", "b.go": "x\n"})
+
+	// How wide a line shows on a terminal with tab stops every 8 columns
+	shown := func(line string) int {
+		width := 0
+		for _, c := range line {
+			if c == '\t' {
+				width = (width/8 + 1) * 8
+			} else {
+				width++
+			}
+		}
+		return width
+	}
+
+	for _, rank := range []bool{false, true} {
+		r := f.counter()
+		r.ShowRank = rank
+		if out := f.reviewers(r); !strings.Contains(out, long) {
+			t.Errorf("Expected no truncation by default, got:\n%s", out)
+		}
+
+		r.MaxWidth = 34
+		out := f.reviewers(r)
+		if !rank && !strings.Contains(out, "\na-very-long-reviewer-n…\t75.00%\ntom@git-reviewer.com\t25.00%\n") {
+			t.Errorf("Expected the reviewer column cut to 23 characters, got:\n%s", out)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+			if w := shown(line); w > r.MaxWidth {
+				t.Errorf("Got a line %d wide with rank %v, expected at most %d:\n%s", w, rank, r.MaxWidth, out)
+			}
+		}
+		if strings.Contains(out, long) || !strings.Contains(out, "a-very-long-re") {
+			t.Errorf("Expected the long reviewer cut short with rank %v, got:\n%s", rank, out)
+		}
+		if !strings.Contains(out, "…\t75.00%") || !strings.Contains(out, "\t25.00%") {
+			t.Errorf("Expected scores after the cut reviewers with rank %v, got:\n%s", rank, out)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		In       string
+		Width    int
+		Expected string
+	}{
+		{"abe@git-reviewer.com", 0, "abe@git-reviewer.com"},
+		{"abe@git-reviewer.com", 20, "abe@git-reviewer.com"},
+		{"abe@git-reviewer.com", 7, "abe@gi…"},
+		{"Zoë <zoe@git-reviewer.com>", 4, "Zoë…"},
+	}
+
+	for _, c := range cases {
+		if actual := truncate(c.In, c.Width); actual != c.Expected {
+			t.Errorf("Got '%s' truncating '%s' to %d, expected '%s'\n", actual, c.In, c.Width, c.Expected)
+		}
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	var paths []string
+	for i := 0; i < 8; i++ {
+		p := fmt.Sprintf("file%d.go", i)
+		paths = append(paths, p)
+		f.commit("abe@git-reviewer.com", map[string]string{p: "1\n2\n"})
+	}
+
+	running, err := ioutil.TempDir("", "git-reviewer-running")
+	if err != nil {
+		t.Fatalf("Unable to create directory: %v\n", err)
+	}
+	defer os.RemoveAll(running)
+
+	for _, limit := range []int{1, 2} {
+		// Every blame marks itself running for a while, and notes how many
+		// were running when it started
+		log := filepath.Join(running, fmt.Sprintf("limit%d.log", limit))
+		r := f.counter()
+		r.Concurrency = limit
+		wrapGit(r, running, `if [ "$sub" = blame ]; then
+				m="$0/$$.run"; touch "$m"; ls "$0" | grep -c '\.run$' >> "$0/`+filepath.Base(log)+`"
+				sleep 0.2; "$@"; s=$?; rm -f "$m"; exit $s
+			fi; exec "$@"`)
+
+		stats, err := r.FindReviewerStats(paths)
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+		if len(stats) != 1 || stats[0].Count != 16 {
+			t.Errorf("Got %v with concurrency %d, expected abe with 16 lines\n", stats, limit)
+		}
+
+		out, err := ioutil.ReadFile(log)
+		if err != nil {
+			t.Fatalf("Unable to read the blame log: %v\n", err)
+		}
+		counts := strings.Fields(string(out))
+		if len(counts) != len(paths) {
+			t.Errorf("Got %d blames with concurrency %d, expected %d\n", len(counts), limit, len(paths))
+		}
+		for _, c := range counts {
+			if n, _ := strconv.Atoi(c); n < 1 || n > limit {
+				t.Errorf("Got %d blames running at once, expected at most %d\n", n, limit)
+			}
+		}
+	}
+}
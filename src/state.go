@@ -0,0 +1,203 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// StateStore keeps the Stats found for a range of history, so that asking
+// again about the same commits and paths, over the same window and with the
+// same options, needn't blame them again. Keys are opaque to the store.
+type StateStore interface {
+	// Load returns the Stats saved under key, and whether there were any.
+	Load(key string) (Stats, bool, error)
+
+	// Save keeps s under key, replacing whatever was there.
+	Save(key string, s Stats) error
+}
+
+// MemoryStateStore keeps state for as long as the process runs. The zero
+// value is ready to use, and it is safe to share between goroutines.
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[string]Stats
+}
+
+// Load returns a copy of the Stats saved under key.
+func (m *MemoryStateStore) Load(key string) (Stats, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.states[key]
+	return copyStats(s), ok, nil
+}
+
+// Save keeps a copy of s under key, so later changes to s don't leak in.
+func (m *MemoryStateStore) Save(key string, s Stats) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.states == nil {
+		m.states = make(map[string]Stats)
+	}
+	m.states[key] = copyStats(s)
+
+	return nil
+}
+
+// JSONFileStateStore keeps state in a JSON file at Path, so it survives
+// between runs. The file is created on the first Save, along with any missing
+// parent directories. Saves replace the file whole, so readers never see it
+// half written, but concurrent saves from several processes may lose one
+// another's entries.
+type JSONFileStateStore struct {
+	Path string
+}
+
+// savedStat is a Stat as JSONFileStateStore writes it, along with the
+// precision its String shows the score at.
+type savedStat struct {
+	*Stat
+	Precision int `json:"precision,omitempty"`
+}
+
+// Load returns the Stats saved under key. A missing file holds nothing.
+func (j JSONFileStateStore) Load(key string) (Stats, bool, error) {
+	states, err := j.read()
+	if err != nil {
+		return nil, false, err
+	}
+
+	saved, ok := states[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	s := make(Stats, len(saved))
+	for i, stat := range saved {
+		s[i] = stat.Stat
+		s[i].precision = stat.Precision
+	}
+
+	return s, true, nil
+}
+
+// Save keeps s under key alongside everything already in the file.
+func (j JSONFileStateStore) Save(key string, s Stats) error {
+	states, err := j.read()
+	if err != nil {
+		return err
+	}
+
+	saved := make([]savedStat, len(s))
+	for i, stat := range s {
+		saved[i] = savedStat{Stat: stat, Precision: stat.precision}
+	}
+	states[key] = saved
+
+	data, err := json.Marshal(states)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode state")
+	}
+
+	dir := filepath.Dir(j.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "unable to create %s", dir)
+	}
+
+	tmp, err := ioutil.TempFile(dir, filepath.Base(j.Path)+".")
+	if err != nil {
+		return errors.Wrapf(err, "unable to write %s", j.Path)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "unable to write %s", j.Path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "unable to write %s", j.Path)
+	}
+
+	return errors.Wrapf(os.Rename(tmp.Name(), j.Path), "unable to write %s", j.Path)
+}
+
+// read decodes the whole file.
+func (j JSONFileStateStore) read() (map[string][]savedStat, error) {
+	states := make(map[string][]savedStat)
+
+	data, err := ioutil.ReadFile(j.Path)
+	if os.IsNotExist(err) {
+		return states, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "unable to read %s", j.Path)
+	}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s", j.Path)
+	}
+
+	return states, nil
+}
+
+// copyStats copies s deeply enough that neither copy can change the other.
+func copyStats(s Stats) Stats {
+	if s == nil {
+		return nil
+	}
+
+	c := make(Stats, len(s))
+	for i, stat := range s {
+		dup := *stat
+		dup.Reasons = append([]string(nil), stat.Reasons...)
+		c[i] = &dup
+	}
+
+	return c
+}
+
+// stateKey identifies the suggestions for paths between the base and HEAD by
+// the commits at either end, so it changes as soon as either branch moves.
+// The window of history and a hash of the options that change scoring are
+// part of it too, so it also changes when the default Since moves on, or
+// when the counter is configured differently.
+func (r *ContributionCounter) stateKey(paths []string) (string, error) {
+	base, err := r.baseCommit()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := r.backend().Resolve(r, "HEAD")
+	if err != nil {
+		return "", errors.Wrap(err, "unable to resolve HEAD")
+	}
+
+	// Neither where the counter runs nor how scores are shown changes them
+	cfg := r.describeConfig()
+	cfg.WorkDir, cfg.MaxWidth, cfg.ScorePrecision = "", 0, 0
+	cfg.Concurrency, cfg.RateLimit, cfg.CommandPrefix = 0, 0, nil
+	options, err := json.Marshal(cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to encode options")
+	}
+
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+
+	return fmt.Sprintf("%s..%s:%s..%s:%x:%s", base.Hash, head, r.since(), r.Until,
+		sha1.Sum(options), strings.Join(sorted, "\x00")), nil
+}
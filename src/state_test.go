@@ -0,0 +1,116 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStateStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-reviewer-state")
+	if err != nil {
+		t.Fatalf("Unable to create state directory: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	saved := Stats{
+		{Reviewer: "abe@git-reviewer.com", Percentage: 0.75, Name: "Abe", Count: 3,
+			LastCommit: "2017-01-02", Files: 1, Rank: 1, Reasons: []string{ReasonLineOwnership}},
+		{Reviewer: "george@git-reviewer.com", Percentage: 0.25, Count: 1,
+			LastCommit: "2017-01-01", Files: 1, Rank: 2},
+	}
+	saved[0].precision = WholeNumbers
+
+	stores := map[string]StateStore{
+		"memory": &MemoryStateStore{},
+		"json":   JSONFileStateStore{Path: filepath.Join(dir, "nested", "state.json")},
+	}
+
+	for name, store := range stores {
+		if _, ok, err := store.Load("a..b"); ok || err != nil {
+			t.Errorf("Expected an empty %s store, got ok %v and error %v\n", name, ok, err)
+		}
+
+		if err := store.Save("a..b", saved); err != nil {
+			t.Fatalf("Unexpected error saving to %s store: %v\n", name, err)
+		}
+		if err := store.Save("a..c", saved[:1]); err != nil {
+			t.Fatalf("Unexpected error saving to %s store: %v\n", name, err)
+		}
+
+		// Changing what was saved doesn't change what is loaded
+		saved[0].Count++
+		loaded, ok, err := store.Load("a..b")
+		saved[0].Count--
+		if !ok || err != nil {
+			t.Fatalf("Expected %s store to load state, got ok %v and error %v\n", name, ok, err)
+		}
+		if !reflect.DeepEqual(loaded, saved) {
+			t.Errorf("Got %v back from %s store, expected %v\n", loaded, name, saved)
+		}
+
+		if loaded, _, _ := store.Load("a..c"); len(loaded) != 1 {
+			t.Errorf("Got %d Stats under the second key of %s store, expected 1\n", len(loaded), name)
+		}
+	}
+}
+
+func TestFindReviewerStatsState(t *testing.T) {
+	f := twoAuthorFixture(t)
+	defer f.cleanup()
+
+	store := &MemoryStateStore{}
+	r := f.counter()
+	r.StateStore = store
+	paths := []string{"main.go", "util.go"}
+
+	found, err := r.FindReviewerStats(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+
+	key, err := r.stateKey(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error keying state: %v\n", err)
+	}
+	if saved, ok, _ := store.Load(key); !ok || !reflect.DeepEqual(saved, found) {
+		t.Fatalf("Got saved state %v, expected %v\n", saved, found)
+	}
+
+	// While neither end of the range moves, the saved state is the answer.
+	sentinel := Stats{{Reviewer: "saved@git-reviewer.com", Percentage: 1, Rank: 1}}
+	store.Save(key, sentinel)
+	if again, err := r.FindReviewerStats(paths); err != nil || !reflect.DeepEqual(again, sentinel) {
+		t.Errorf("Got %v and error %v after saving state, expected %v\n", again, err, sentinel)
+	}
+
+	// A different window or different options is a different question
+	for name, change := range map[string]func(*ContributionCounter){
+		"since":  func(r *ContributionCounter) { r.Since = "2000-01-01" },
+		"until":  func(r *ContributionCounter) { r.Until = "2100-01-01" },
+		"weight": func(r *ContributionCounter) { r.Weight = WeightCommits },
+	} {
+		changed := f.counter()
+		changed.StateStore = store
+		change(changed)
+		if again, err := changed.FindReviewerStats(paths); err != nil || reflect.DeepEqual(again, sentinel) {
+			t.Errorf("Got %v and error %v with a different %s, expected fresh Stats\n", again, err, name)
+		}
+	}
+
+	f.commit("me@git-reviewer.com", map[string]string{"doc.go": "f\nz\nw\n"})
+	moved, err := r.FindReviewerStats(paths)
+	if err != nil {
+		t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+	}
+	if !reflect.DeepEqual(moved, found) {
+		t.Errorf("Got %v once HEAD moved, expected %v\n", moved, found)
+	}
+}
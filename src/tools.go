@@ -0,0 +1,90 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultToolAuthors are the ToolAuthors used when none are given: the
+// identities formatters and other bots commonly commit under, such as GitHub
+// Apps like dependabot[bot] and no-reply service addresses. GitHub's per-user
+// noreply addresses belong to people and don't match. Patterns are path.Match
+// globs, so brackets must be escaped to match literally. They are also the
+// bots left out of suggested reviewers; see DefaultIgnoredAuthorPatterns.
+var DefaultToolAuthors = []string{
+	`*\[bot\]`,
+	`*\[bot\]@*`,
+	"noreply@*",
+	"no-reply@*",
+	"*-bot",
+	"*-bot@*",
+	"bot@*",
+	"clang-format*",
+	"prettier*",
+	"gofmt*",
+	"rubocop*",
+}
+
+// toolAuthors returns the patterns identifying tool identities.
+func (r *ContributionCounter) toolAuthors() []string {
+	if len(r.ToolAuthors) > 0 {
+		return r.ToolAuthors
+	}
+
+	return DefaultToolAuthors
+}
+
+// isTool reports whether a commit author's name or email matches one of the
+// tool patterns, ignoring case.
+func isTool(patterns []string, name, email string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		for _, id := range []string{name, email} {
+			if ok, _ := path.Match(pattern, strings.ToLower(id)); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// skipToolChurn leaves out the paths most of whose commits since Since are by
+// tools, when SkipToolChurn is set. Their recent history is reformatting
+// rather than anything a reviewer would need to know about, so whoever shows
+// up in it says little about who understands the file. Files without recent
+// commits are kept.
+func (r *ContributionCounter) skipToolChurn(paths []string) ([]string, error) {
+	if !r.SkipToolChurn || len(paths) == 0 {
+		return paths, nil
+	}
+
+	records, err := r.ContributionRecords(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := r.toolAuthors()
+	commits := make(map[string]int)
+	tools := make(map[string]int)
+	for _, rec := range records {
+		commits[rec.File]++
+		if isTool(patterns, rec.Author, rec.Email) {
+			tools[rec.File]++
+		}
+	}
+
+	var kept []string
+	for _, p := range paths {
+		if 2*tools[p] <= commits[p] {
+			kept = append(kept, p)
+		}
+	}
+
+	return kept, nil
+}
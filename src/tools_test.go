@@ -0,0 +1,59 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSkipToolChurn(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"fmt.go": "a\nb\n", "real.go": "1\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"real.go": "1\n2\n"})
+	// The formatter rewrote every line of fmt.go, twice
+	f.commitAs("prettier-bot", "prettier-bot@git-reviewer.com", map[string]string{"fmt.go": "a;\nb;\n"})
+	f.commitAs("dependabot[bot]", "support@github.com", map[string]string{"fmt.go": "a; \nb; \n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"fmt.go": "a; \nb; \nc\n", "real.go": "1\n2\n3\n"})
+
+	cases := []struct {
+		Skip     bool
+		Patterns []string
+		Expected []string
+	}{
+		{false, nil, []string{"abe@git-reviewer.com", "george@git-reviewer.com", "support@github.com"}},
+		{true, nil, []string{"abe@git-reviewer.com", "george@git-reviewer.com"}},
+		// Only one of the three recent fmt.go commits is then a tool's
+		{true, []string{"prettier*"}, []string{"abe@git-reviewer.com", "george@git-reviewer.com", "support@github.com"}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.SkipToolChurn = c.Skip
+		r.ToolAuthors = c.Patterns
+		r.MaxReviewers = 10
+		// Which files are skipped is what's under test, not who is suggested
+		r.IncludeBots = true
+
+		stats, err := r.FindReviewerStats([]string{"fmt.go", "real.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		var reviewers []string
+		for _, stat := range stats {
+			reviewers = append(reviewers, stat.Reviewer)
+		}
+		sort.Strings(reviewers)
+		if !reflect.DeepEqual(reviewers, c.Expected) {
+			t.Errorf("Got reviewers %v skipping %v with %v, expected %v\n", reviewers, c.Skip, c.Patterns, c.Expected)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+// Window is a span of days, given as "YYYY-MM-DD" like Since and Until. Both
+// ends are included.
+type Window struct {
+	Since string
+	Until string
+}
+
+// String names the window as "since..until".
+func (w Window) String() string {
+	return w.Since + ".." + w.Until
+}
+
+// ReviewerTrend finds the top reviewers of the paths counting only the
+// experience gained within each window, to show how ownership shifts over
+// time. Results are keyed by Window.String. Windows where nobody worked on the
+// paths have no Stats.
+func (r *ContributionCounter) ReviewerTrend(paths []string, buckets []Window) (map[string]Stats, error) {
+	defer r.startRun()()
+
+	trend := make(map[string]Stats)
+	for _, w := range buckets {
+		windowed := *r
+		windowed.Since, windowed.Until = w.Since, w.Until
+
+		stats, err := windowed.FindReviewerStats(paths)
+		if _, ok := err.(NoReviewersErr); err != nil && !ok {
+			return nil, err
+		}
+
+		trend[w.String()] = stats
+	}
+
+	return trend, nil
+}
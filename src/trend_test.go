@@ -0,0 +1,57 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReviewerTrend(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	early := time.Date(2017, 2, 1, 12, 0, 0, 0, time.UTC)
+	late := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+	// abe leads early on both lines and commits, george later
+	f.commitAt("abe@git-reviewer.com", early, map[string]string{"a.go": "1\n2\n"})
+	f.commitAt("abe@git-reviewer.com", early.Add(time.Hour), map[string]string{"a.go": "1\n2\n3\n"})
+	f.commitAt("george@git-reviewer.com", early.Add(2*time.Hour), map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commitAt("george@git-reviewer.com", late, map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n"})
+	f.commitAt("george@git-reviewer.com", late.Add(time.Hour), map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n7\n8\n"})
+	f.commitAt("jane@git-reviewer.com", late.Add(2*time.Hour), map[string]string{"a.go": "1\n2\n3\n4\n5\n6\n7\n8\n9\n"})
+
+	buckets := []Window{
+		{Since: "2017-01-01", Until: "2017-06-30"},
+		{Since: "2017-07-01", Until: "2017-12-31"},
+		{Since: "2018-01-01", Until: "2018-06-30"},
+	}
+
+	for _, weight := range []Weight{WeightBlame, WeightCommits} {
+		r := f.counter()
+		r.Weight = weight
+
+		trend, err := r.ReviewerTrend([]string{"a.go"}, buckets)
+		if err != nil {
+			t.Fatalf("Unexpected error finding trend: %v\n", err)
+		}
+
+		expected := map[string]string{
+			"2017-01-01..2017-06-30": "abe@git-reviewer.com",
+			"2017-07-01..2017-12-31": "george@git-reviewer.com",
+		}
+		for window, top := range expected {
+			if stats := trend[window]; len(stats) == 0 || stats[0].Reviewer != top {
+				t.Errorf("Got reviewers %v in %s with weight %d, expected %s first\n",
+					stats, window, weight, top)
+			}
+		}
+
+		if stats, ok := trend["2018-01-01..2018-06-30"]; !ok || len(stats) != 0 {
+			t.Errorf("Got reviewers %v for an empty window, expected none\n", stats)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// logMu keeps lines from concurrent blames from interleaving.
+var logMu sync.Mutex
+
+// verbosef writes a line of progress or error information to Log when
+// Verbose is set.
+func (r *ContributionCounter) verbosef(format string, args ...interface{}) {
+	if !r.Verbose {
+		return
+	}
+
+	var w io.Writer = os.Stderr
+	if r.Log != nil {
+		w = r.Log
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	fmt.Fprintf(w, format, args...)
+}
+
+// output runs a command built by gitCommand and returns what it writes to
+// stdout, once RateLimit allows. In verbose mode the command is logged
+// before it runs, along with whatever it writes to stderr if it fails.
+func (r *ContributionCounter) output(cmd *exec.Cmd) ([]byte, error) {
+	if err := r.throttle(); err != nil {
+		return nil, err
+	}
+	r.verbosef("+ %s\n", shellJoin(cmd.Args))
+
+	out, err := cmd.Output()
+	if err != nil {
+		r.verbosef("%s failed: %v\n", cmd.Args[0], err)
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			r.verbosef("%s", exit.Stderr)
+		}
+	}
+
+	return out, err
+}
+
+// shellJoin writes out a command line, single-quoting the arguments a shell
+// would otherwise split or expand, so logged commands can be pasted back in.
+// Nothing expands within single quotes, and a quote inside an argument
+// closes them, is escaped with a backslash, and opens them again.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`*?[]#~&|;<>(){}!") {
+			arg = "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+		}
+		quoted[i] = arg
+	}
+
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,100 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+// Weight chooses what counts as a unit of experience with a file.
+type Weight int
+
+const (
+	// WeightBlame counts the lines each collaborator owns in the base
+	// according to git blame. This is the default.
+	WeightBlame Weight = iota
+
+	// WeightCommits counts the commits each collaborator made to a file.
+	WeightCommits
+
+	// WeightLines counts the lines each collaborator added to and deleted
+	// from a file across their commits, so one commit rewriting a file
+	// outweighs a handful of one-line fixes. Binary changes count as a line.
+	WeightLines
+)
+
+// commitCounts attributes one unit of experience per commit that touched each
+// path, or with WeightLines, one per line the commit changed in it. With
+// SquashConsecutive, a run of consecutive commits to a file by the same
+// author only counts once, so rapid-fire work-in-progress commits don't
+// outweigh a single considered change; their lines still all count.
+func (r *ContributionCounter) commitCounts(paths []string) (contributions, error) {
+	records, err := r.ContributionRecords(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	byFile := make(contributions)
+	for _, rec := range records {
+		lines := byFile[rec.File]
+
+		// Records arrive newest first, so runs of commits by the same author
+		// are adjacent in each file's list regardless of direction.
+		var units int64
+		if r.Weight == WeightLines {
+			units = rec.Added + rec.Deleted
+		}
+
+		if r.SquashConsecutive && len(lines) > 0 && lines[len(lines)-1].author == rec.Email {
+			if units > 0 {
+				lines[len(lines)-1].units = lines[len(lines)-1].size() + units
+			}
+			continue
+		}
+
+		byFile[rec.File] = append(lines, attribution{
+			author: rec.Email,
+			rev:    rec.SHA,
+			date:   r.day(rec.When),
+			units:  units,
+		})
+	}
+
+	return byFile, nil
+}
+
+// Aggregation chooses how a reviewer's experience with each changed file
+// combines into their overall score.
+type Aggregation int
+
+const (
+	// AggregateSum pools every file together, so a score is the share of all
+	// the changed files' lines (or commits) a reviewer has. Large files
+	// dominate. This is the default.
+	AggregateSum Aggregation = iota
+
+	// AggregateMax scores reviewers by the one file where they have the
+	// largest share, favoring deep experience with part of a change.
+	AggregateMax
+
+	// AggregateMean averages a reviewer's share of each file, counting files
+	// they haven't worked on as zero, so every file has equal say regardless
+	// of its size. With ActivityWeight, active files count for more.
+	AggregateMean
+)
+
+// addFile folds a reviewer's share of a single file into their score. inFile
+// holds how much each reviewer has in a file with size units of experience in
+// total, and w is the file's activity weight.
+func (a Aggregation) addFile(scores, inFile map[string]float64, size, w float64) {
+	for author, n := range inFile {
+		share := n / size
+		switch a {
+		case AggregateMax:
+			if share > scores[author] {
+				scores[author] = share
+			}
+		case AggregateMean:
+			scores[author] += w * share
+		}
+	}
+}
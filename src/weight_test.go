@@ -0,0 +1,207 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSquashConsecutiveCommits(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n"})
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": "1\n2\n3\n4\n5\n"})
+
+	cases := []struct {
+		Squash   bool
+		Expected map[string]int64
+	}{
+		{false, map[string]int64{"abe@git-reviewer.com": 4, "bob@git-reviewer.com": 1}},
+		{true, map[string]int64{"abe@git-reviewer.com": 2, "bob@git-reviewer.com": 1}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Weight = WeightCommits
+		r.SquashConsecutive = c.Squash
+
+		stats, err := r.FindReviewerStats([]string{"a.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Errorf("Got %d reviewers, expected %d\n", len(stats), len(c.Expected))
+		}
+		for _, stat := range stats {
+			if expected := c.Expected[stat.Reviewer]; stat.Count != expected {
+				t.Errorf("Got %d commits for %s with squash %v, expected %d\n",
+					stat.Count, stat.Reviewer, c.Squash, expected)
+			}
+		}
+	}
+}
+
+func TestWeightLines(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// abe writes the file in one go; tom makes three one-line fixes to it
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i + 1)
+	}
+	f.commit("abe@git-reviewer.com", map[string]string{"a.go": strings.Join(lines, "\n") + "\n"})
+	for i := 0; i < 3; i++ {
+		lines[i] = "fix " + lines[i]
+		f.commit("tom@git-reviewer.com", map[string]string{"a.go": strings.Join(lines, "\n") + "\n"})
+	}
+
+	cases := []struct {
+		Weight   Weight
+		Expected map[string]int64
+		Top      string
+	}{
+		{WeightCommits, map[string]int64{"abe@git-reviewer.com": 1, "tom@git-reviewer.com": 3}, "tom@git-reviewer.com"},
+		{WeightLines, map[string]int64{"abe@git-reviewer.com": 20, "tom@git-reviewer.com": 6}, "abe@git-reviewer.com"},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Weight = c.Weight
+
+		stats, err := r.FindReviewerStats([]string{"a.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Fatalf("Got %d reviewers, expected %d\n", len(stats), len(c.Expected))
+		}
+		if stats[0].Reviewer != c.Top {
+			t.Errorf("Got %s ranked first with weight %v, expected %s\n", stats[0].Reviewer, c.Weight, c.Top)
+		}
+		for _, stat := range stats {
+			if expected := c.Expected[stat.Reviewer]; stat.Count != expected {
+				t.Errorf("Got count %d for %s with weight %v, expected %d\n",
+					stat.Count, stat.Reviewer, c.Weight, expected)
+			}
+		}
+	}
+}
+
+func TestAggregation(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	f.commit("abe@git-reviewer.com", map[string]string{"big.go": "1\n2\n3\n4\n5\n6\n"})
+	f.commit("bob@git-reviewer.com", map[string]string{"big.go": "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"})
+	f.commit("george@git-reviewer.com", map[string]string{"small.go": "1\n2\n"})
+
+	cases := []struct {
+		Aggregation Aggregation
+		Expected    map[string]float64
+		Top         string
+	}{
+		{AggregateSum, map[string]float64{
+			"abe@git-reviewer.com": 0.5, "bob@git-reviewer.com": 4.0 / 12, "george@git-reviewer.com": 2.0 / 12,
+		}, "abe@git-reviewer.com"},
+		// george owns all of one file, so ranks first despite fewer lines
+		{AggregateMax, map[string]float64{
+			"abe@git-reviewer.com": 0.6, "bob@git-reviewer.com": 0.4, "george@git-reviewer.com": 1,
+		}, "george@git-reviewer.com"},
+		{AggregateMean, map[string]float64{
+			"abe@git-reviewer.com": 0.3, "bob@git-reviewer.com": 0.2, "george@git-reviewer.com": 0.5,
+		}, "george@git-reviewer.com"},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.Aggregation = c.Aggregation
+
+		stats, err := r.FindReviewerStats([]string{"big.go", "small.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		top := stats[0]
+		for _, stat := range stats {
+			if math.Abs(stat.Percentage-c.Expected[stat.Reviewer]) > 1e-9 {
+				t.Errorf("Got score %f for %s with aggregation %d, expected %f\n",
+					stat.Percentage, stat.Reviewer, c.Aggregation, c.Expected[stat.Reviewer])
+			}
+			if stat.Percentage > top.Percentage {
+				top = stat
+			}
+		}
+
+		if top.Reviewer != c.Top {
+			t.Errorf("Got top reviewer %s with aggregation %d, expected %s\n",
+				top.Reviewer, c.Aggregation, c.Top)
+		}
+	}
+}
+
+func TestMovedLines(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	block := "func parseConfiguration(path string) error {\n" +
+		"\treturn loadConfigurationFromDisk(path)\n" +
+		"}\n"
+	other := "func writeConfiguration(path string) error {\n" +
+		"\treturn saveConfigurationToDisk(path)\n" +
+		"}\n"
+
+	f.commit("abe@git-reviewer.com", map[string]string{
+		"a.go": block + other,
+		"b.go": "package b\n" + block,
+	})
+	// george reorders a.go and moves b.go's copy of the block into c.go
+	f.commit("george@git-reviewer.com", map[string]string{
+		"a.go": other + "\n" + block,
+		"b.go": "package b\n",
+		"c.go": block,
+	})
+
+	cases := []struct {
+		Path     string
+		Copies   bool
+		Expected map[string]int64
+	}{
+		{"a.go", false, map[string]int64{"abe@git-reviewer.com": 6, "george@git-reviewer.com": 1}},
+		{"c.go", false, map[string]int64{"george@git-reviewer.com": 3}},
+		{"c.go", true, map[string]int64{"abe@git-reviewer.com": 3}},
+	}
+
+	for _, c := range cases {
+		r := f.counter()
+		r.DetectCopies = c.Copies
+
+		stats, err := r.FindReviewerStats([]string{c.Path})
+		if err != nil {
+			t.Fatalf("Unexpected error finding reviewers: %v\n", err)
+		}
+
+		if len(stats) != len(c.Expected) {
+			t.Errorf("Got reviewers %v for %s with copies %v, expected %v\n",
+				stats, c.Path, c.Copies, c.Expected)
+		}
+		for _, stat := range stats {
+			if expected := c.Expected[stat.Reviewer]; stat.Count != expected {
+				t.Errorf("Got %d lines for %s in %s with copies %v, expected %d\n",
+					stat.Count, stat.Reviewer, c.Path, c.Copies, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WhyNot explains why reviewer isn't among the suggestions for the changed
+// paths, such as being excluded, having too little history, or ranking below
+// the cutoff. The reviewer is matched like an approver in
+// EvaluateSuggestions, or by the name they commit under. When they are
+// suggested after all, WhyNot says so.
+func (r *ContributionCounter) WhyNot(reviewer string, paths []string) (string, error) {
+	if r == nil {
+		return "", ErrNilCounter
+	}
+	defer r.startRun()()
+
+	if err := r.prepare(); err != nil {
+		return "", err
+	}
+
+	// Everyone with history on the paths, before anything filters them
+	unfiltered := *r
+	unfiltered.ExcludeSelf = false
+	unfiltered.ExcludedReviewers = nil
+	unfiltered.IncludeBots = true
+	unfiltered.MinCommits = 0
+	unfiltered.Eligibility = nil
+	unfiltered.Veto = nil
+	unfiltered.CriticalPaths = nil
+
+	all, _, err := unfiltered.candidates(paths)
+	if err != nil {
+		return "", err
+	}
+	if err := r.nameReviewers(all); err != nil {
+		return "", err
+	}
+
+	want := identityKey(reviewerKey(reviewer, r.Mailmap))
+	var stat *Stat
+	for _, s := range all {
+		if strings.EqualFold(s.Name, reviewer) {
+			stat = s
+		}
+		for _, id := range r.identities(s.Reviewer) {
+			if id == want {
+				stat = s
+			}
+		}
+		if stat != nil {
+			break
+		}
+	}
+	if stat == nil {
+		return fmt.Sprintf("%s has no history on these files", reviewer), nil
+	}
+	who := stat.Reviewer
+
+	// The filters in the order score applies them
+	if r.ExcludeSelf {
+		self := *r
+		self.ExcludedReviewers = nil
+		self.IncludeBots = true
+		kept, err := self.exclude(Stats{stat})
+		if err != nil {
+			return "", err
+		}
+		if len(kept) == 0 {
+			return fmt.Sprintf("%s is the author of the change and ExcludeSelf is set", who), nil
+		}
+	}
+	if r.isExcluded(stat) {
+		return fmt.Sprintf("%s is excluded by ExcludedReviewers", who), nil
+	}
+	bots, err := r.botPatterns()
+	if err != nil {
+		return "", err
+	}
+	if isBot(bots, stat) {
+		return fmt.Sprintf("%s looks like a bot and IncludeBots isn't set", who), nil
+	}
+	if r.MinCommits > 0 && stat.Count < int64(r.MinCommits) {
+		return fmt.Sprintf("%s has a count of %d on these files, below MinCommits of %d",
+			who, stat.Count, r.MinCommits), nil
+	}
+	eligible, err := r.filterEligible(Stats{stat})
+	if err != nil {
+		return "", err
+	}
+	if len(eligible) == 0 {
+		return fmt.Sprintf("%s isn't eligible to review by Eligibility", who), nil
+	}
+	if r.Veto != nil && r.Veto(*stat, paths) {
+		return fmt.Sprintf("%s is vetoed by Veto", who), nil
+	}
+
+	// Nothing filtered them, so it comes down to how they rank
+	final, _, err := r.candidates(paths)
+	if err != nil {
+		return "", err
+	}
+	place := 1
+	for _, s := range final {
+		if s.Percentage > stat.Percentage {
+			place++
+		}
+	}
+
+	top, err := r.selectTop(final)
+	if _, ok := err.(ErrInsufficientReviewers); err != nil && !ok {
+		return "", err
+	}
+	for _, s := range top {
+		if s.Reviewer == who {
+			return fmt.Sprintf("%s is suggested", who), nil
+		}
+	}
+
+	limit := r.reviewerLimit()
+	switch {
+	case r.PostProcess != nil:
+		return fmt.Sprintf("%s ranks %d of %d candidates and is left out by PostProcess",
+			who, place, len(final)), nil
+	case place <= limit:
+		return fmt.Sprintf("%s ranks %d of %d candidates by experience but is passed over for reviewers preferred by PreferFastReviewers or PreferWorkingHours",
+			who, place, len(final)), nil
+	}
+
+	return fmt.Sprintf("%s ranks %d of %d candidates, below the top %d suggested",
+		who, place, len(final), limit), nil
+}
@@ -0,0 +1,77 @@
+/*
+Sniperkit-Bot
+- Status: analyzed
+*/
+
+package gitreviewers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWhyNot(t *testing.T) {
+	f := newFixture(t)
+	defer f.cleanup()
+
+	// Each author appends lines to a.go, owning as many as they wrote
+	lines := ""
+	for _, author := range []struct {
+		Email string
+		Lines int
+	}{
+		{"abe@git-reviewer.com", 5},
+		{"george@git-reviewer.com", 3},
+		{"dependabot[bot]@users.noreply.github.com", 2},
+		{"carol@git-reviewer.com", 1},
+		{"dave@git-reviewer.com", 4},
+		{"erin@git-reviewer.com", 2},
+		{"vic@git-reviewer.com", 2},
+		{"me@git-reviewer.com", 2},
+	} {
+		for i := 0; i < author.Lines; i++ {
+			lines += author.Email + "\n"
+		}
+		f.commit(author.Email, map[string]string{"a.go": lines})
+	}
+	f.commit("zed@git-reviewer.com", map[string]string{"b.go": "z\n"})
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("me@git-reviewer.com", map[string]string{"a.go": "0\n"})
+
+	r := f.counter()
+	r.MaxReviewers = 1
+	r.ExcludeSelf = true
+	r.SelfIdentity = "me@git-reviewer.com"
+	r.ExcludedReviewers = []string{"dave"}
+	r.MinCommits = 2
+	r.Eligibility = &denyChecker{deny: "erin@git-reviewer.com", calls: make(map[string]int)}
+	r.Veto = func(reviewer Stat, paths []string) bool {
+		return reviewer.Reviewer == "vic@git-reviewer.com"
+	}
+
+	cases := []struct {
+		Reviewer string
+		Expected string
+	}{
+		{"zed@git-reviewer.com", "no history on these files"},
+		{"me@git-reviewer.com", "ExcludeSelf"},
+		{"dave", "ExcludedReviewers"},
+		{"dependabot[bot]@users.noreply.github.com", "bot"},
+		{"carol@git-reviewer.com", "below MinCommits of 2"},
+		{"erin@git-reviewer.com", "isn't eligible"},
+		{"vic@git-reviewer.com", "vetoed"},
+		{"george@git-reviewer.com", "ranks 2 of 2 candidates, below the top 1"},
+		{"ABE@git-reviewer.com", "is suggested"},
+	}
+
+	for _, c := range cases {
+		why, err := r.WhyNot(c.Reviewer, []string{"a.go"})
+		if err != nil {
+			t.Fatalf("Unexpected error asking about %s: %v\n", c.Reviewer, err)
+		}
+
+		if !strings.Contains(why, c.Expected) {
+			t.Errorf("Got reason '%s' for %s, expected it to mention '%s'\n", why, c.Reviewer, c.Expected)
+		}
+	}
+}